@@ -2,29 +2,176 @@ package cmdconfig
 
 import (
 	"flag"
+	"strings"
+	"sync"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
-var cfgFile string
+// Loader reads config files into structs with its own viper instance, file paths, profile, env prefix and search paths
+type Loader struct {
+	v       *viper.Viper
+	files   []string
+	profile string
+	name    string
+	paths   []string
+
+	envEnabled  bool
+	envPrefix   string
+	envReplacer *strings.Replacer
+	flags       map[string]*pflag.Flag
+
+	mu      sync.Mutex
+	layers  []string          // files merged by the last read, in order
+	sources map[string]string // key to the file that set it last
+
+	watchOnce sync.Once
+	watchMu   sync.Mutex
+	onChange  []func(error)
+	watchers  int
+}
+
+// the package level functions use the global viper instance, so viper.Get keeps working
+var defaultLoader = &Loader{v: viper.GetViper(), name: "config", flags: map[string]*pflag.Flag{}}
+
+func NewLoader() *Loader {
+	return &Loader{v: viper.New(), name: "config", flags: map[string]*pflag.Flag{}}
+}
+
+// DefaultLoader returns the loader used by the package level functions
+func DefaultLoader() *Loader {
+	return defaultLoader
+}
+
+// Viper returns the viper instance of l
+func (l *Loader) Viper() *viper.Viper {
+	return l.v
+}
+
+// SetConfigFile sets the config file, the config flag sets it too
+func (l *Loader) SetConfigFile(file string) {
+	l.files = []string{file}
+}
+
+// SetConfigFiles sets config files merged in order, a later file overrides the keys of the earlier ones
+func (l *Loader) SetConfigFiles(files ...string) {
+	l.files = append([]string{}, files...)
+}
+
+// SetProfile merges <name>.<profile><ext> beside the first config file on top of it, e.g. config.prod.yaml for config.yaml,
+// the profile flag sets it too
+func (l *Loader) SetProfile(profile string) {
+	l.profile = profile
+}
+
+// SetConfigName sets the file name without extension searched in the config paths when no config file is set, default "config"
+func (l *Loader) SetConfigName(name string) {
+	l.name = name
+}
+
+// AddConfigPath adds a directory searched for the config name when no config file is set, default "."
+func (l *Loader) AddConfigPath(path string) {
+	l.paths = append(l.paths, path)
+}
 
 func SetConfigFlag() {
-	flag.StringVar(&cfgFile, "config", "./config.yaml", "config file")
+	defaultLoader.SetConfigFlag()
+}
+
+// SetConfigFlag adds the flags -config, repeated or separated by "," for several files merged in order, and -profile
+func (l *Loader) SetConfigFlag() {
+	l.defaultConfigFile()
+	flag.Var(&filesFlag{l: l}, "config", "config files, merged in order")
+	flag.StringVar(&l.profile, "profile", l.profile, "config profile, merges config.<profile>.yaml on top of the config file")
 	flag.Parse()
 }
 
 func SetConfigFlagByCobra(cmd *cobra.Command) {
-	cmd.PersistentFlags().StringVar(&cfgFile, "config", "./config.yaml", "config file")
+	defaultLoader.SetConfigFlagByCobra(cmd)
+}
+
+// SetConfigFlagByCobra adds the persistent flags --config and --profile like SetConfigFlag
+func (l *Loader) SetConfigFlagByCobra(cmd *cobra.Command) {
+	l.defaultConfigFile()
+	cmd.PersistentFlags().Var(&filesFlag{l: l}, "config", "config files, merged in order")
+	cmd.PersistentFlags().StringVar(&l.profile, "profile", l.profile, "config profile, merges config.<profile>.yaml on top of the config file")
+}
+
+func (l *Loader) defaultConfigFile() {
+	if len(l.files) == 0 {
+		l.files = []string{"./config.yaml"}
+	}
+}
+
+// filesFlag replaces the default files when first set, then appends
+type filesFlag struct {
+	l   *Loader
+	set bool
+}
+
+func (f *filesFlag) String() string {
+	if f == nil || f.l == nil {
+		return ""
+	}
+	return strings.Join(f.l.files, ",")
+}
+
+func (f *filesFlag) Set(value string) error {
+	if !f.set {
+		f.l.files = nil
+		f.set = true
+	}
+	for _, file := range strings.Split(value, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			f.l.files = append(f.l.files, file)
+		}
+	}
+	return nil
+}
+
+func (f *filesFlag) Type() string {
+	return "strings"
 }
 
-// GetConfig to struct, configs should be the point of config struct
 func GetConfig(configs interface{}) error {
-	viper.SetConfigFile(cfgFile)
-	if err := viper.ReadInConfig(); err != nil {
+	return defaultLoader.GetConfig(configs)
+}
+
+// GetConfig to struct, configs should be the point of config struct,
+// the config files, the profile file and their includes are merged, unset fields are filled by their `default:"..."` tags, then the `validate:"..."` tags are checked
+func (l *Loader) GetConfig(configs interface{}) error {
+	if err := l.read(); err != nil {
 		return err
 	}
-	if err := viper.Unmarshal(configs); err != nil {
+	return l.decode(configs)
+}
+
+// decode fills configs from the config already read, with env, flags and defaults, then validates it
+func (l *Loader) decode(configs interface{}) error {
+	if err := l.bindEnvs("", configs); err != nil {
 		return err
 	}
-	return nil
+	l.setDefaults("", configs)
+	if err := l.v.Unmarshal(configs); err != nil {
+		return err
+	}
+	return Validate("", configs)
+}
+
+func GetConfigKey(key string, configs interface{}) error {
+	return defaultLoader.GetConfigKey(key, configs)
+}
+
+// GetConfigKey to struct, the section key of the config read by GetConfig, e.g. "logger"
+func (l *Loader) GetConfigKey(key string, configs interface{}) error {
+	if err := l.bindEnvs(key, configs); err != nil {
+		return err
+	}
+	l.setDefaults(key, configs)
+	if err := l.v.UnmarshalKey(key, configs); err != nil {
+		return err
+	}
+	return Validate(key, configs)
 }
@@ -0,0 +1,75 @@
+package cmdconfig
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// KeySource is where the effective value of a config key came from
+type KeySource struct {
+	Key    string
+	Source string // "flag --name", "env NAME", the config file or "default"
+}
+
+// Sources returns the source of every effective key after GetConfig, sorted by key,
+// env and flag keys are known once bound or used by GetConfig and GetConfigKey
+func (l *Loader) Sources() []KeySource {
+	l.mu.Lock()
+	files := l.sources
+	l.mu.Unlock()
+
+	keys := l.v.AllKeys()
+	sort.Strings(keys)
+	sources := make([]KeySource, 0, len(keys))
+	for _, key := range keys {
+		source := "default"
+		if flag, ok := l.flags[key]; ok && flag.Changed {
+			source = "flag --" + flag.Name
+		} else if name := l.envName(key); l.envEnabled && os.Getenv(name) != "" {
+			source = "env " + name
+		} else if file, ok := files[key]; ok {
+			source = file
+		}
+		sources = append(sources, KeySource{Key: key, Source: source})
+	}
+	return sources
+}
+
+// PrintSources writes the merged files in order then the source of every effective key
+func (l *Loader) PrintSources(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "FILES")
+	for i, file := range l.ConfigFiles() {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\n", i+1, file)
+	}
+	_, _ = fmt.Fprintln(tw, "\nKEY\tSOURCE")
+	for _, source := range l.Sources() {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", source.Key, source.Source)
+	}
+	return tw.Flush()
+}
+
+func DebugCommand() *cobra.Command {
+	return defaultLoader.DebugCommand()
+}
+
+// DebugCommand returns the command "config-sources" printing which file, env or flag each effective key came from,
+// add it to the command given to SetConfigFlagByCobra so --config and --profile apply
+func (l *Loader) DebugCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "config-sources",
+		Short: "Print the merged config files and where each effective key came from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := l.read(); err != nil {
+				return err
+			}
+			return l.PrintSources(cmd.OutOrStdout())
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package cmdconfig
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+func AutomaticEnv(prefix string, replacer *strings.Replacer) {
+	defaultLoader.AutomaticEnv(prefix, replacer)
+}
+
+// AutomaticEnv lets environment variables override the config file, the variable of a key is the prefix
+// and the key joined by "_", upper cased, with "." and "-" replaced by "_" unless replacer is given,
+// e.g. prefix "APP" and key "db.dsn" read APP_DB_DSN.
+// Precedence from high to low: flags bound by BindFlag, env, config file, defaults
+func (l *Loader) AutomaticEnv(prefix string, replacer *strings.Replacer) {
+	if replacer == nil {
+		replacer = strings.NewReplacer(".", "_", "-", "_")
+	}
+	l.v.SetEnvPrefix(prefix)
+	l.v.SetEnvKeyReplacer(replacer)
+	l.v.AutomaticEnv()
+	l.envEnabled = true
+	l.envPrefix = prefix
+	l.envReplacer = replacer
+}
+
+func BindFlag(key string, flag *pflag.Flag) error {
+	return defaultLoader.BindFlag(key, flag)
+}
+
+// BindFlag uses the value of flag for key when the flag is set on the command line, e.g.
+// BindFlag("db.dsn", cmd.Flags().Lookup("dsn")), the flag default is used when neither env nor file set key
+func (l *Loader) BindFlag(key string, flag *pflag.Flag) error {
+	if err := l.v.BindPFlag(key, flag); err != nil {
+		return err
+	}
+	l.flags[strings.ToLower(key)] = flag
+	return nil
+}
+
+func BindFlags(flags *pflag.FlagSet) error {
+	return defaultLoader.BindFlags(flags)
+}
+
+// BindFlags binds every flag of flags to the key of the same name
+func (l *Loader) BindFlags(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err == nil {
+			err = l.BindFlag(flag.Name, flag)
+		}
+	})
+	return err
+}
+
+func SetDefault(key string, value interface{}) {
+	defaultLoader.SetDefault(key, value)
+}
+
+// SetDefault sets the value of key when no flag, env or config file sets it
+func (l *Loader) SetDefault(key string, value interface{}) {
+	l.v.SetDefault(key, value)
+}
+
+// bindEnvs binds the env of every key of configs under prefix, viper.Unmarshal only sees env of keys it already knows
+func (l *Loader) bindEnvs(prefix string, configs interface{}) error {
+	if !l.envEnabled {
+		return nil
+	}
+	var err error
+	walkKeys(reflect.TypeOf(configs), prefix, func(key string, _ reflect.StructField) {
+		if err == nil {
+			err = l.v.BindEnv(key)
+		}
+	})
+	return err
+}
+
+// envName returns the env variable read for key, like viper
+func (l *Loader) envName(key string) string {
+	name := strings.ToUpper(key)
+	if l.envPrefix != "" {
+		name = strings.ToUpper(l.envPrefix + "_" + key)
+	}
+	if l.envReplacer != nil {
+		name = l.envReplacer.Replace(name)
+	}
+	return name
+}
@@ -0,0 +1,81 @@
+package cmdconfig
+
+import (
+	"reflect"
+	"strings"
+)
+
+// walkKeys calls fn with the config key of every leaf field of t, named by their mapstructure tags like viper.Unmarshal,
+// a struct nested in itself, such as type node struct{ Next *node }, is walked once
+func walkKeys(t reflect.Type, prefix string, fn func(key string, field reflect.StructField)) {
+	walkStruct(t, prefix, map[reflect.Type]bool{}, fn)
+}
+
+// walkStruct walks t unless it is already on path, the structs being walked
+func walkStruct(t reflect.Type, prefix string, path map[reflect.Type]bool, fn func(key string, field reflect.StructField)) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || path[t] {
+		return
+	}
+	path[t] = true
+	defer delete(path, t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, squash := fieldKey(field)
+		if name == "-" {
+			continue
+		}
+		key := prefix
+		if !squash {
+			key = joinKey(prefix, name)
+		}
+		if hasKeys(field.Type) {
+			walkStruct(field.Type, key, path, fn)
+			continue
+		}
+		if key != "" {
+			fn(key, field)
+		}
+	}
+}
+
+// fieldKey returns the key name of field, lower cased as viper keys are case insensitive
+func fieldKey(field reflect.StructField) (name string, squash bool) {
+	name = field.Name
+	if tag, ok := field.Tag.Lookup("mapstructure"); ok {
+		parts := strings.SplitN(tag, ",", 2)
+		if parts[0] != "" {
+			name = parts[0]
+		}
+		squash = len(parts) == 2 && strings.Contains(parts[1], "squash")
+	}
+	return strings.ToLower(name), squash
+}
+
+// hasKeys reports whether t is a struct with exported fields, time.Time and the like are leaf values
+func hasKeys(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
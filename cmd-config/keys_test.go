@@ -0,0 +1,25 @@
+package cmdconfig
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type keysNode struct {
+	Name string    `mapstructure:"name"`
+	Next *keysNode `mapstructure:"next"`
+	Tags []keysNode
+}
+
+func TestWalkKeysRecursiveType(t *testing.T) {
+	var keys []string
+	walkKeys(reflect.TypeOf(&keysNode{}), "", func(key string, _ reflect.StructField) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	want := []string{"name", "tags"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+}
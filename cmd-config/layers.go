@@ -0,0 +1,203 @@
+package cmdconfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// IncludeKey lists files merged before the file declaring it, relative to its directory, e.g.
+//
+//	include: [common.yaml, db.yaml]
+const IncludeKey = "include"
+
+// layer is one config file with its settings, without the include key
+type layer struct {
+	file     string
+	settings map[string]interface{}
+}
+
+// read merges the config files in order, each after the files it includes, the profile file after the first one.
+// When no config file is set the config name is searched in the config paths.
+// A failed read keeps the current config. Like viper itself, reading keys while a read replaces the config is unsafe,
+// use the snapshot of a Watcher from other goroutines
+func (l *Loader) read() error {
+	var layers []layer
+	seen := map[string]bool{}
+	files := l.files
+	if len(files) == 0 {
+		base, err := l.search()
+		if err != nil {
+			return err
+		}
+		files = []string{base}
+	}
+	for i, file := range files {
+		var err error
+		if layers, err = readLayer(file, layers, seen, nil); err != nil {
+			return err
+		}
+		if i == 0 && l.profile != "" {
+			if layers, err = readLayer(profileFile(file, l.profile), layers, seen, nil); err != nil {
+				return fmt.Errorf("profile %s: %w", l.profile, err)
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(layers))
+	sources := map[string]string{}
+	merged := map[string]interface{}{}
+	for _, layer := range layers {
+		paths = append(paths, layer.file)
+		flattenKeys("", layer.settings, func(key string) {
+			sources[key] = layer.file
+		})
+		mergeSettings(merged, layer.settings)
+	}
+	// replace the config of viper, its own merge skips values whose type differs, such as an int of yaml and a float of json.
+	// viper cannot swap its config at once, so everything that can fail is done first: the empty document is checked
+	// on a scratch instance, the config is cleared only when the merged map is ready and merging it cannot fail
+	empty := ""
+	if strings.EqualFold(filepath.Ext(layers[0].file), ".json") {
+		empty = "{}"
+	}
+	check := viper.New()
+	check.SetConfigFile(layers[0].file)
+	if err := check.ReadConfig(strings.NewReader(empty)); err != nil {
+		return fmt.Errorf("%s: %w", layers[0].file, err)
+	}
+	l.v.SetConfigFile(layers[0].file)
+	_ = l.v.ReadConfig(strings.NewReader(empty))
+	_ = l.v.MergeConfigMap(merged)
+	l.mu.Lock()
+	l.layers = paths
+	l.sources = sources
+	l.mu.Unlock()
+	return nil
+}
+
+// search finds the config name in the config paths, "." by default
+func (l *Loader) search() (string, error) {
+	v := viper.New()
+	v.SetConfigName(l.name)
+	paths := l.paths
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	for _, path := range paths {
+		v.AddConfigPath(path)
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return "", err
+	}
+	return v.ConfigFileUsed(), nil
+}
+
+// readLayer appends the layers of the files included by file then file itself, a file already merged is skipped,
+// stack is the chain of including files
+func readLayer(file string, layers []layer, seen map[string]bool, stack []string) ([]layer, error) {
+	file = filepath.Clean(file)
+	for _, including := range stack {
+		if including == file {
+			return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), file)
+		}
+	}
+	if seen[file] {
+		return layers, nil
+	}
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	settings := v.AllSettings()
+	includes, err := includeFiles(settings[IncludeKey])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	delete(settings, IncludeKey)
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(file), include)
+		}
+		if layers, err = readLayer(include, layers, seen, append(stack, file)); err != nil {
+			return nil, err
+		}
+	}
+	seen[file] = true
+	return append(layers, layer{file: file, settings: settings}), nil
+}
+
+// includeFiles accepts a file or a list of files
+func includeFiles(value interface{}) ([]string, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case []interface{}:
+		files := make([]string, 0, len(value))
+		for _, file := range value {
+			s, ok := file.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: not a file: %v", IncludeKey, file)
+			}
+			files = append(files, s)
+		}
+		return files, nil
+	default:
+		return nil, fmt.Errorf("%s: should be a file or a list of files, got %T", IncludeKey, value)
+	}
+}
+
+// mergeSettings sets the values of src in dst, nested maps are merged
+func mergeSettings(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if nested, ok := value.(map[string]interface{}); ok {
+			if current, ok := dst[key].(map[string]interface{}); ok {
+				mergeSettings(current, nested)
+				continue
+			}
+			copied := map[string]interface{}{}
+			mergeSettings(copied, nested)
+			value = copied
+		}
+		dst[key] = value
+	}
+}
+
+// profileFile returns dir/config.prod.yaml for dir/config.yaml and the profile prod
+func profileFile(file, profile string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + profile + ext
+}
+
+// flattenKeys calls fn with the key of every leaf of settings, nested keys joined by "."
+func flattenKeys(prefix string, settings map[string]interface{}, fn func(key string)) {
+	for name, value := range settings {
+		key := joinKey(prefix, name)
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) != 0 {
+			flattenKeys(key, nested, fn)
+			continue
+		}
+		fn(key)
+	}
+}
+
+// ConfigFiles returns the files merged by the last read, in order, including the profile file and the included files
+func (l *Loader) ConfigFiles() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.layers...)
+}
+
+// realPath resolves symlinks, such as the ..data link of a kubernetes config map, "" when file is missing
+func realPath(file string) string {
+	path, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,105 @@
+package cmdconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is a failed validation rule of a config key
+type FieldError struct {
+	Key   string      // config key path, e.g. "db.dsn"
+	Rule  string      // validate tag, e.g. "required"
+	Param string      // parameter of the rule, e.g. "65535" of "max=65535"
+	Value interface{} // the value after unmarshal
+}
+
+func (e FieldError) Error() string {
+	value := fmt.Sprint(e.Value)
+	if s, ok := e.Value.(string); ok {
+		value = fmt.Sprintf("%q", s)
+	}
+	if e.Param != "" {
+		return fmt.Sprintf("%s: %s=%s, got %s", e.Key, e.Rule, e.Param, value)
+	}
+	return fmt.Sprintf("%s: %s, got %s", e.Key, e.Rule, value)
+}
+
+// ValidationError lists all failed rules of a config
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		msgs[i] = field.Error()
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+var (
+	validateOnce sync.Once
+	validate     *validator.Validate
+)
+
+func getValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+		// report config keys instead of Go field names
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _ := fieldKey(field)
+			return name
+		})
+	})
+	return validate
+}
+
+// Validate checks the `validate:"..."` tags of configs, configs should be the point of config struct,
+// keys in the returned *ValidationError are under prefix, e.g. "logger"
+func Validate(prefix string, configs interface{}) error {
+	v := reflect.ValueOf(configs)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	err := getValidator().Struct(v.Interface())
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+	verr := &ValidationError{}
+	for _, fe := range fieldErrors {
+		// the namespace starts with the struct type name
+		key := fe.Namespace()
+		if i := strings.Index(key, "."); i >= 0 {
+			key = key[i+1:]
+		}
+		verr.Fields = append(verr.Fields, FieldError{
+			Key:   joinKey(prefix, key),
+			Rule:  fe.Tag(),
+			Param: fe.Param(),
+			Value: fe.Value(),
+		})
+	}
+	return verr
+}
+
+// setDefaults registers the `default:"..."` tags of configs under prefix as viper defaults,
+// the lowest precedence, the string is converted to the field type on unmarshal
+func (l *Loader) setDefaults(prefix string, configs interface{}) {
+	walkKeys(reflect.TypeOf(configs), prefix, func(key string, field reflect.StructField) {
+		if value, ok := field.Tag.Lookup("default"); ok && !l.v.IsSet(key) {
+			l.v.SetDefault(key, value)
+		}
+	})
+}
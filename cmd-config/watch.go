@@ -0,0 +1,196 @@
+package cmdconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func OnConfigChange(fn func()) {
+	defaultLoader.OnConfigChange(fn)
+}
+
+// OnConfigChange calls fn after one of the config files merged by GetConfig changes and they are read again,
+// the files are watched from the first call, call it after GetConfig. Read errors keep the config and are written to
+// stderr unless a Watcher reports them. The config is replaced on the watching goroutine, viper.Get and GetConfigKey
+// from other goroutines may see missing keys meanwhile, use WatchConfig for a snapshot safe to read from any goroutine
+func (l *Loader) OnConfigChange(fn func()) {
+	l.onConfigChange(func(err error) {
+		if err == nil {
+			fn()
+		}
+	})
+}
+
+func (l *Loader) onConfigChange(fn func(error)) {
+	l.watchMu.Lock()
+	l.onChange = append(l.onChange, fn)
+	l.watchMu.Unlock()
+	l.watchOnce.Do(l.watch)
+}
+
+// watch watches the directories of the merged files, viper only watches a single file
+func (l *Loader) watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "cmdconfig: watch config: %s\n", err.Error())
+		return
+	}
+	dirs := map[string]bool{}
+	real := map[string]string{}
+	// watch the files of the last read, which change when an include is added or removed
+	update := func() {
+		real = map[string]string{}
+		for _, file := range l.ConfigFiles() {
+			real[file] = realPath(file)
+			dir := filepath.Dir(file)
+			if dirs[dir] {
+				continue
+			}
+			if err := watcher.Add(dir); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "cmdconfig: watch config: %s\n", err.Error())
+				continue
+			}
+			dirs[dir] = true
+		}
+	}
+	update()
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !changed(event, real) {
+					continue
+				}
+				err := l.read()
+				update()
+				l.notify(err)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				_, _ = fmt.Fprintf(os.Stderr, "cmdconfig: watch config: %s\n", err.Error())
+			}
+		}
+	}()
+}
+
+// changed reports whether event writes one of files, or a symlinked file now points elsewhere
+func changed(event fsnotify.Event, files map[string]string) bool {
+	name := filepath.Clean(event.Name)
+	if _, ok := files[name]; ok && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+		return true
+	}
+	for file, path := range files {
+		if path != "" && realPath(file) != path {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Loader) notify(err error) {
+	l.watchMu.Lock()
+	fns := make([]func(error), len(l.onChange))
+	copy(fns, l.onChange)
+	watchers := l.watchers
+	l.watchMu.Unlock()
+	if err != nil && watchers == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "cmdconfig: reload config: %s\n", err.Error())
+	}
+	for _, fn := range fns {
+		fn(err)
+	}
+}
+
+// Watcher keeps the latest valid config, replaced as a whole when the config file changes
+type Watcher struct {
+	loader  *Loader
+	typ     reflect.Type
+	current atomic.Value
+
+	mu       sync.Mutex
+	onChange []func(old, new interface{})
+	onError  func(error)
+}
+
+func WatchConfig(configs interface{}, onChange func(old, new interface{})) (*Watcher, error) {
+	return defaultLoader.WatchConfig(configs, onChange)
+}
+
+// WatchConfig watches the config files merged by GetConfig, configs is the point of config struct filled by GetConfig.
+// On change the files are read into a new struct of the same type and validated, when valid it becomes the snapshot
+// returned by Config and onChange is called with the old and new points, otherwise the error is reported
+// by the OnError handler, stderr by default, and the snapshot is kept
+func (l *Loader) WatchConfig(configs interface{}, onChange func(old, new interface{})) (*Watcher, error) {
+	typ := reflect.TypeOf(configs)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("configs should be the point of config struct")
+	}
+	w := &Watcher{loader: l, typ: typ}
+	w.current.Store(configs)
+	if onChange != nil {
+		w.onChange = append(w.onChange, onChange)
+	}
+	l.watchMu.Lock()
+	l.watchers++
+	l.watchMu.Unlock()
+	l.onConfigChange(w.reload)
+	return w, nil
+}
+
+// Config returns the current snapshot, a point of the type passed to WatchConfig, do not modify it
+func (w *Watcher) Config() interface{} {
+	return w.current.Load()
+}
+
+// OnChange adds a callback called with the old and new snapshots after a valid change
+func (w *Watcher) OnChange(fn func(old, new interface{})) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onChange = append(w.onChange, fn)
+}
+
+// OnError sets the handler of read, parse and validation errors of changed files
+func (w *Watcher) OnError(fn func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = fn
+}
+
+func (w *Watcher) reload(err error) {
+	next := reflect.New(w.typ.Elem()).Interface()
+	if err == nil {
+		err = w.loader.decode(next)
+	}
+
+	w.mu.Lock()
+	callbacks := make([]func(old, new interface{}), len(w.onChange))
+	copy(callbacks, w.onChange)
+	onError := w.onError
+	w.mu.Unlock()
+
+	if err != nil {
+		err = fmt.Errorf("reload config: %w", err)
+		if onError != nil {
+			onError(err)
+			return
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "cmdconfig: %s\n", err.Error())
+		return
+	}
+	old := w.current.Load()
+	w.current.Store(next)
+	for _, fn := range callbacks {
+		fn(old, next)
+	}
+}
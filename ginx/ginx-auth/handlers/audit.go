@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"fmt"
+
+	contextkey "github.com/PengShaw/go-common/ginx/ginx-context-key"
+	"github.com/PengShaw/go-common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// audit writes an auth event to the audit logger, result is failure when err is not nil
+func audit(c *gin.Context, actor, action, target string, err error) {
+	fields := []logger.Field{logger.String("client_ip", c.ClientIP())}
+	if reqID := c.GetString(contextkey.ReqIDContextKey); reqID != "" {
+		fields = append(fields, logger.String("req_id", reqID))
+	}
+	result := logger.AuditSuccess
+	if err != nil {
+		result = logger.AuditFailure
+		fields = append(fields, logger.String("reason", err.Error()))
+	}
+	auditErr := logger.Audit(logger.AuditEvent{Actor: actor, Action: action, Target: target, Result: result, Fields: fields})
+	if auditErr != nil {
+		logger.FromContext(c.Request.Context()).Errorf("write audit event failed: [%s]", auditErr.Error())
+	}
+}
+
+// currentActor is the user authenticated by middlewares.AuthRequired
+func currentActor(c *gin.Context) string {
+	if userID, ok := c.Get(contextkey.UserIDContextKey); ok {
+		return fmt.Sprintf("user_id:%v", userID)
+	}
+	return "anonymous"
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"github.com/PengShaw/go-common/ginx/ginx-auth/casbinx"
 	"github.com/PengShaw/go-common/ginx/ginx-auth/models"
 	contextkey "github.com/PengShaw/go-common/ginx/ginx-context-key"
@@ -22,6 +23,7 @@ func CreateAuthPermission(c *gin.Context) {
 	}
 
 	err := casbinx.AddPermission(jsonSchema.GroupID, jsonSchema.SourceID)
+	audit(c, currentActor(c), "permission.create", fmt.Sprintf("group_id:%d resource_id:%d", jsonSchema.GroupID, jsonSchema.SourceID), err)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": err.Error()})
 		return
@@ -89,6 +91,7 @@ func DeleteAuthPermission(c *gin.Context) {
 	}
 
 	err = casbinx.DeletePermission(permission.AuthUserGroupID, permission.AuthResourceID)
+	audit(c, currentActor(c), "permission.delete", fmt.Sprintf("group_id:%d resource_id:%d", permission.AuthUserGroupID, permission.AuthResourceID), err)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": err.Error()})
 		return
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	jwttoken "github.com/PengShaw/go-common/ginx/ginx-auth/jwt-token"
 	"github.com/PengShaw/go-common/ginx/ginx-auth/models"
 	contextkey "github.com/PengShaw/go-common/ginx/ginx-context-key"
@@ -24,11 +25,12 @@ func LoginHandler(c *gin.Context) {
 
 	// query & verify user
 	user, err := models.VerifyUser(jsonSchema.Username, jsonSchema.Password)
+	audit(c, jsonSchema.Username, "user.login", "user:"+jsonSchema.Username, err)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"msg": err.Error()})
 		return
 	}
-	logger.Infof("user (%d)[%s] logined", user.ID, user.Username)
+	logger.FromContext(c.Request.Context()).Infof("user (%d)[%s] logined", user.ID, user.Username)
 
 	// generate token
 	token, err := jwttoken.Generate(user)
@@ -53,6 +55,7 @@ func CreateUser(c *gin.Context) {
 	}
 
 	err := models.CreateUser(jsonSchema.Username, jsonSchema.Password, jsonSchema.UserGroupID)
+	audit(c, currentActor(c), "user.create", fmt.Sprintf("user:%s group_id:%d", jsonSchema.Username, jsonSchema.UserGroupID), err)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": err.Error()})
 		return
@@ -111,6 +114,7 @@ func DeleteUser(c *gin.Context) {
 	}
 
 	err = models.DeleteUser(uint(id))
+	audit(c, currentActor(c), "user.delete", "user:"+user.Username, err)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": err.Error()})
 		return
@@ -141,6 +145,7 @@ func UserChangePassword(c *gin.Context) {
 	}
 
 	err := models.UserChangePassword(userID, jsonSchema.Password)
+	audit(c, currentActor(c), "user.change_password", fmt.Sprintf("user_id:%d", userID), err)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": err.Error()})
 		return
@@ -170,6 +175,7 @@ func RegroupUser(c *gin.Context) {
 	}
 
 	err = models.RegroupUser(jsonSchema.UserID, jsonSchema.UserGroupID)
+	audit(c, currentActor(c), "user.regroup", fmt.Sprintf("user:%s group_id:%d", user.Username, jsonSchema.UserGroupID), err)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": err.Error()})
 		return
@@ -0,0 +1,35 @@
+package ginxlogger
+
+import (
+	"github.com/PengShaw/go-common/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// SetupLevelHandler registers GET and PUT /log/level to read and change the global log level at runtime,
+// router must sit behind authentication, any client switching production to debug level could read debug output and fill the disk
+func SetupLevelHandler(router gin.IRoutes) {
+	router.GET("/log/level", GetLevelHandler)
+	router.PUT("/log/level", SetLevelHandler)
+}
+
+func GetLevelHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": logger.GetLevel()})
+}
+
+func SetLevelHandler(c *gin.Context) {
+	type levelUpdate struct {
+		Level string `json:"level" binding:"required"`
+	}
+
+	var jsonSchema levelUpdate
+	if err := c.ShouldBindJSON(&jsonSchema); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+	if err := logger.SetLevel(jsonSchema.Level); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"level": logger.GetLevel()})
+}
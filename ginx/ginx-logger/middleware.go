@@ -19,15 +19,24 @@ func Middleware() gin.HandlerFunc {
 		}
 		c.Set(contextkey.ReqIDContextKey, id.String())
 
+		// request-scoped logger, available to handlers by FromContext, with the trace of a tracing middleware registered before
+		reqLogger := logger.Ctx(c.Request.Context()).WithField("req_id", id.String())
+		c.Request = c.Request.WithContext(reqLogger.WithContext(c.Request.Context()))
+
 		// 处理请求
 		c.Next()
 
-		l := logger.WithField("status_code", c.Writer.Status())
+		l := logger.FromContext(c.Request.Context())
+		l = l.WithField("status_code", c.Writer.Status())
 		l = l.WithField("latency_time", time.Now().Sub(startTime))
 		l = l.WithField("client_ip", c.ClientIP())
 		l = l.WithField("req_method", c.Request.Method)
 		l = l.WithField("req_uri", c.Request.RequestURI)
-		l = l.WithField("req_id", id.String())
 		l.Info("")
 	}
 }
+
+// FromContext returns the request-scoped logger set by Middleware, gin.Context itself does not expose request context values
+func FromContext(c *gin.Context) *logger.Logger {
+	return logger.FromContext(c.Request.Context())
+}
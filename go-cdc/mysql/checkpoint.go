@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"errors"
+	"time"
+
+	gomysql "github.com/go-mysql-org/go-mysql/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Checkpoint persists the last synced binlog position
+type Checkpoint interface {
+	// Load returns false when no position has been saved yet
+	Load() (gomysql.Position, bool, error)
+	Save(pos gomysql.Position) error
+}
+
+// CDCCheckpoint Table
+type CDCCheckpoint struct {
+	Name      string `gorm:"primaryKey;size:191"`
+	File      string
+	Pos       uint32
+	UpdatedAt time.Time
+}
+
+// GormCheckpoint stores the position in a row of the cdc_checkpoints table,
+// replicas sharing the name resume from each other's position
+type GormCheckpoint struct {
+	db   *gorm.DB
+	name string
+}
+
+func NewGormCheckpoint(db *gorm.DB, name string) (*GormCheckpoint, error) {
+	if err := db.AutoMigrate(&CDCCheckpoint{}); err != nil {
+		return nil, err
+	}
+	return &GormCheckpoint{db: db, name: name}, nil
+}
+
+func (c *GormCheckpoint) Load() (gomysql.Position, bool, error) {
+	var record CDCCheckpoint
+	err := c.db.Where("name = ?", c.name).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return gomysql.Position{}, false, nil
+	}
+	if err != nil {
+		return gomysql.Position{}, false, err
+	}
+	return gomysql.Position{Name: record.File, Pos: record.Pos}, true, nil
+}
+
+func (c *GormCheckpoint) Save(pos gomysql.Position) error {
+	return c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"file", "pos", "updated_at"}),
+	}).Create(&CDCCheckpoint{Name: c.name, File: pos.Name, Pos: pos.Pos}).Error
+}
@@ -0,0 +1,153 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+)
+
+// control holds the pause state and the delivery rate limit of a CDC
+type control struct {
+	mu      sync.Mutex
+	paused  bool
+	resumed chan struct{} // closed by Resume
+	limiter *rate.Limiter
+	events  uint64
+	leading int32
+}
+
+func newControl(eventsPerSecond float64) *control {
+	c := &control{resumed: make(chan struct{}), limiter: rate.NewLimiter(rate.Inf, 1)}
+	c.setRateLimit(eventsPerSecond)
+	return c
+}
+
+func (c *control) setRateLimit(eventsPerSecond float64) {
+	if eventsPerSecond <= 0 {
+		c.limiter.SetLimit(rate.Inf)
+		return
+	}
+	burst := int(eventsPerSecond)
+	if burst < 1 {
+		burst = 1
+	}
+	c.limiter.SetBurst(burst)
+	c.limiter.SetLimit(rate.Limit(eventsPerSecond))
+}
+
+// wait blocks while paused and until the rate limiter admits one more event
+func (c *control) wait(ctx context.Context) error {
+	c.mu.Lock()
+	paused, resumed := c.paused, c.resumed
+	c.mu.Unlock()
+	if paused {
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	atomic.AddUint64(&c.events, 1)
+	return nil
+}
+
+// Pause stops delivering events to table handlers, the position is kept and the binlog connection stays open
+func (cdc *CDC) Pause() {
+	cdc.ctl.mu.Lock()
+	defer cdc.ctl.mu.Unlock()
+	if !cdc.ctl.paused {
+		cdc.ctl.paused = true
+		cdc.ctl.resumed = make(chan struct{})
+	}
+}
+
+// Resume continues delivering events from where Pause stopped
+func (cdc *CDC) Resume() {
+	cdc.ctl.mu.Lock()
+	defer cdc.ctl.mu.Unlock()
+	if cdc.ctl.paused {
+		cdc.ctl.paused = false
+		close(cdc.ctl.resumed)
+	}
+}
+
+// SetRateLimit changes the maximal number of row events delivered per second, 0 means unlimited
+func (cdc *CDC) SetRateLimit(eventsPerSecond float64) {
+	cdc.ctl.setRateLimit(eventsPerSecond)
+	cdc.ctl.mu.Lock()
+	cdc.Options.EventsPerSecond = eventsPerSecond
+	cdc.ctl.mu.Unlock()
+}
+
+type Status struct {
+	Paused          bool    `json:"paused"`
+	EventsPerSecond float64 `json:"events_per_second"` // 0 means unlimited
+	Events          uint64  `json:"events"`            // row events delivered to table handlers
+	Leader          bool    `json:"leader"`            // always true without Elector
+	Position        string  `json:"position"`
+	DelaySeconds    uint32  `json:"delay_seconds"`
+}
+
+func (cdc *CDC) Status() Status {
+	cdc.ctl.mu.Lock()
+	status := Status{
+		Paused:          cdc.ctl.paused,
+		EventsPerSecond: cdc.Options.EventsPerSecond,
+	}
+	cdc.ctl.mu.Unlock()
+	status.Events = atomic.LoadUint64(&cdc.ctl.events)
+	status.Leader = cdc.Options.Elector == nil || atomic.LoadInt32(&cdc.ctl.leading) == 1
+
+	cdc.mu.Lock()
+	status.Position = cdc.canal.SyncedPosition().String()
+	status.DelaySeconds = cdc.canal.GetDelay()
+	cdc.mu.Unlock()
+	return status
+}
+
+// AdminHandler reports Status on GET, and on PUT applies a JSON body like
+// {"paused": true, "events_per_second": 100}, where omitted fields are left unchanged
+func (cdc *CDC) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req struct {
+				Paused          *bool    `json:"paused"`
+				EventsPerSecond *float64 `json:"events_per_second"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"msg": err.Error()})
+				return
+			}
+			if req.Paused != nil {
+				if *req.Paused {
+					cdc.Pause()
+				} else {
+					cdc.Resume()
+				}
+			}
+			if req.EventsPerSecond != nil {
+				cdc.SetRateLimit(*req.EventsPerSecond)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"msg": "method not allowed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, cdc.Status())
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
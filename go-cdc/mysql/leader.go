@@ -0,0 +1,324 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/canal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNotLeader = errors.New("not the leader")
+
+// Elector decides which replica streams binlog events
+type Elector interface {
+	// Campaign blocks until leadership is acquired or ctx is done
+	Campaign(ctx context.Context) error
+	// Lost is closed when the leadership acquired by the last Campaign is lost
+	Lost() <-chan struct{}
+	// Resign gives up leadership so a standby can take over immediately
+	Resign() error
+}
+
+func (cdc *CDC) listenAsLeader() error {
+	elector := cdc.Options.Elector
+	for {
+		if err := elector.Campaign(cdc.ctx); err != nil {
+			if cdc.ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		_, _ = fmt.Fprintln(DefaultWriter, "Info: became leader, start streaming")
+		atomic.StoreInt32(&cdc.ctl.leading, 1)
+
+		handler := &binlogHandler{cdc: cdc}
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- cdc.listen(handler)
+		}()
+
+		select {
+		case err := <-errCh:
+			atomic.StoreInt32(&cdc.ctl.leading, 0)
+			_ = elector.Resign()
+			return err
+		case <-elector.Lost():
+			_, _ = fmt.Fprintln(DefaultWriter, "Warn: leadership lost, back to standby")
+			atomic.StoreInt32(&handler.fenced, 1)
+			atomic.StoreInt32(&cdc.ctl.leading, 0)
+			cdc.mu.Lock()
+			cdc.closeCanal()
+			cdc.mu.Unlock()
+			<-errCh
+		}
+
+		// a closed canal can not run again
+		c, err := canal.NewCanal(cdc.config)
+		if err != nil {
+			return err
+		}
+		cdc.mu.Lock()
+		if cdc.ctx.Err() != nil {
+			cdc.mu.Unlock()
+			c.Close()
+			return nil
+		}
+		cdc.canal = c
+		cdc.canalClosed = false
+		cdc.mu.Unlock()
+	}
+}
+
+// LockElector elects by MySQL advisory lock GET_LOCK, held by a dedicated connection.
+// The server releases the lock as soon as the leader's connection dies, so standbys take over within about a second.
+type LockElector struct {
+	db            *gorm.DB
+	name          string
+	CheckInterval time.Duration // how often the leader verifies it still holds the lock, default 1s
+
+	mu   sync.Mutex
+	conn *sql.Conn
+	lost chan struct{}
+	stop context.CancelFunc
+}
+
+func NewLockElector(db *gorm.DB, name string) *LockElector {
+	return &LockElector{db: db, name: name, CheckInterval: time.Second, lost: make(chan struct{})}
+}
+
+func (e *LockElector) Campaign(ctx context.Context) error {
+	sqlDB, err := e.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		// wait at most 1s on the server side, so ctx is checked regularly
+		var got sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 1)", e.name).Scan(&got); err != nil {
+			_ = conn.Close()
+			return err
+		}
+		if got.Valid && got.Int64 == 1 {
+			break
+		}
+		if !got.Valid {
+			// NULL is an error such as running out of memory or the thread being killed, it returns at once
+			select {
+			case <-ctx.Done():
+				_ = conn.Close()
+				return ctx.Err()
+			case <-time.After(e.CheckInterval):
+			}
+		}
+	}
+
+	keepCtx, stop := context.WithCancel(context.Background())
+	lost := make(chan struct{})
+	e.mu.Lock()
+	e.conn = conn
+	e.lost = lost
+	e.stop = stop
+	e.mu.Unlock()
+	go e.keep(keepCtx, conn, lost)
+	return nil
+}
+
+func (e *LockElector) keep(ctx context.Context, conn *sql.Conn, lost chan struct{}) {
+	ticker := time.NewTicker(e.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var held sql.NullInt64
+		err := conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?) = CONNECTION_ID()", e.name).Scan(&held)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil || !held.Valid || held.Int64 != 1 {
+			_ = conn.Close()
+			close(lost)
+			return
+		}
+	}
+}
+
+func (e *LockElector) Lost() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lost
+}
+
+func (e *LockElector) Resign() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return ErrNotLeader
+	}
+	e.stop()
+	_, err := e.conn.ExecContext(context.Background(), "DO RELEASE_LOCK(?)", e.name)
+	if closeErr := e.conn.Close(); err == nil {
+		err = closeErr
+	}
+	e.conn = nil
+	return err
+}
+
+// CDCLease Table
+type CDCLease struct {
+	Name      string `gorm:"primaryKey;size:191"`
+	Holder    string
+	ExpiresAt time.Time
+}
+
+// LeaseElector elects by a lease row in the cdc_leases table, renewed by the leader every TTL/3.
+// The leader stops streaming once TTL*2/3 passed without a successful renewal, a third of the TTL before a standby
+// may take over, clock skew between replicas and the time to close the canal must stay below that margin.
+type LeaseElector struct {
+	db   *gorm.DB
+	name string
+	id   string
+	ttl  time.Duration
+
+	mu   sync.Mutex
+	lost chan struct{}
+	stop context.CancelFunc
+}
+
+// NewLeaseElector creates an elector for lease name, id must be unique among replicas
+func NewLeaseElector(db *gorm.DB, name, id string, ttl time.Duration) (*LeaseElector, error) {
+	if err := db.AutoMigrate(&CDCLease{}); err != nil {
+		return nil, err
+	}
+	if ttl == 0 {
+		ttl = 10 * time.Second
+	}
+	return &LeaseElector{db: db, name: name, id: id, ttl: ttl, lost: make(chan struct{})}, nil
+}
+
+// tryAcquire takes or renews the lease until now+ttl, now is taken before the query so the lease is never assumed longer than it is
+func (e *LeaseElector) tryAcquire(ctx context.Context, now time.Time) (bool, error) {
+	db := e.db.WithContext(ctx)
+	res := db.Model(&CDCLease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", e.name, e.id, now).
+		Updates(map[string]interface{}{"holder": e.id, "expires_at": now.Add(e.ttl)})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+	// first replica ever, create the lease row
+	res = db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&CDCLease{Name: e.name, Holder: e.id, ExpiresAt: now.Add(e.ttl)})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (e *LeaseElector) Campaign(ctx context.Context) error {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	var acquired time.Time
+	for {
+		now := time.Now()
+		ok, err := e.tryAcquire(ctx, now)
+		if err != nil {
+			return err
+		}
+		if ok {
+			acquired = now
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	keepCtx, stop := context.WithCancel(context.Background())
+	lost := make(chan struct{})
+	e.mu.Lock()
+	e.lost = lost
+	e.stop = stop
+	e.mu.Unlock()
+	go e.renew(keepCtx, lost, acquired)
+	return nil
+}
+
+// renew extends the lease every ttl/3. Leadership is given up a third of the ttl before the lease expires,
+// measured from the last successful renewal, so the canal is closed before a standby may take over.
+func (e *LeaseElector) renew(ctx context.Context, lost chan struct{}, renewed time.Time) {
+	timer := time.NewTimer(e.ttl / 3)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		deadline := renewed.Add(e.ttl - e.ttl/3)
+		now := time.Now()
+		if !now.Before(deadline) {
+			close(lost)
+			return
+		}
+		// a hanging query must not hold the leadership past the deadline
+		attemptCtx, cancel := context.WithDeadline(ctx, deadline)
+		ok, err := e.tryAcquire(attemptCtx, now)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if ok {
+			renewed = now
+			timer.Reset(e.ttl / 3)
+			continue
+		}
+		if err == nil {
+			// another replica holds the lease
+			close(lost)
+			return
+		}
+		// transient errors are retried more often until the deadline
+		retry := e.ttl / 12
+		if wait := time.Until(deadline); wait < retry {
+			retry = wait
+		}
+		timer.Reset(retry)
+	}
+}
+
+func (e *LeaseElector) Lost() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lost
+}
+
+func (e *LeaseElector) Resign() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stop == nil {
+		return ErrNotLeader
+	}
+	e.stop()
+	e.stop = nil
+	return e.db.Model(&CDCLease{}).
+		Where("name = ? AND holder = ?", e.name, e.id).
+		Update("expires_at", time.Now()).Error
+}
@@ -1,19 +1,31 @@
 package mysql
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/go-mysql-org/go-mysql/canal"
+	gomysql "github.com/go-mysql-org/go-mysql/mysql"
 	"io"
 	"os"
 	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var DefaultWriter io.Writer = os.Stdout
 
 type CDC struct {
 	canal   *canal.Canal
+	config  *canal.Config
 	Options Options
+
+	mu          sync.Mutex
+	canalClosed bool
+	ctx         context.Context
+	cancel      context.CancelFunc
+	ctl         *control
 }
 
 type Options struct {
@@ -25,6 +37,23 @@ type Options struct {
 	Tables   []Table
 	Flavor   string // flavor is mysql or mariadb, default mysql
 	ServerID uint32
+	// ScanServerID is used by the short replication connections of StartTime and PositionAt, which would drop
+	// a running stream sharing its server id, default ServerID+1, it must differ from ServerID and the other replicas
+	ScanServerID uint32
+
+	// start position, at most one of them can be set, default is the current master position
+	StartGTID string    // GTID set to start after, e.g. "3E11FA47-71CA-11E1-9E33-C80AA9429562:1-23"
+	StartFile string    // binlog file to start from, e.g. "mysql-bin.000003"
+	StartPos  uint32    // position in StartFile, default 4, the first event of the file
+	StartTime time.Time // start from the first transaction at or after this time
+
+	// Checkpoint stores the synced position, when it holds one, streaming resumes from there and start options are ignored
+	Checkpoint         Checkpoint
+	CheckpointInterval time.Duration // minimal interval between two checkpoint saves, default 1s
+	// Elector makes replicas sharing the same Checkpoint stream one at a time, only the leader runs Listen
+	Elector Elector
+	// EventsPerSecond limits row events delivered to table handlers, default 0, unlimited
+	EventsPerSecond float64
 }
 
 type Table struct {
@@ -53,6 +82,16 @@ func (o *Options) init() {
 	if o.ServerID == 0 {
 		o.ServerID = 10001
 	}
+	if o.ScanServerID == 0 {
+		o.ScanServerID = o.ServerID + 1
+		if o.ScanServerID == 0 {
+			// ServerID is the largest server id
+			o.ScanServerID = o.ServerID - 1
+		}
+	}
+	if o.CheckpointInterval == 0 {
+		o.CheckpointInterval = time.Second
+	}
 }
 
 func NewCDC(options *Options) (*CDC, error) {
@@ -75,28 +114,115 @@ func NewCDC(options *Options) (*CDC, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &CDC{canal: c, Options: *options}, nil
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CDC{
+		canal:   c,
+		config:  cfg,
+		Options: *options,
+		ctx:     ctx,
+		cancel:  cancel,
+		ctl:     newControl(options.EventsPerSecond),
+	}, nil
 }
 
+// Listen streams binlog events to table handlers until an error occurs or Close is called.
+// With an Elector set, it waits to become leader first and falls back to standby when leadership is lost.
 func (cdc *CDC) Listen() error {
+	if cdc.Options.startModes() > 1 {
+		return ErrStartOptionsConflict
+	}
+	if cdc.Options.ScanServerID == cdc.Options.ServerID {
+		return ErrScanServerID
+	}
+	if cdc.Options.Elector != nil {
+		return cdc.listenAsLeader()
+	}
+	return cdc.listen(&binlogHandler{cdc: cdc})
+}
+
+// Close stops streaming, Listen returns once the canal is closed
+func (cdc *CDC) Close() {
+	cdc.cancel()
+	cdc.mu.Lock()
+	defer cdc.mu.Unlock()
+	cdc.closeCanal()
+}
+
+// closeCanal must be called with mu held, closing a canal twice panics
+func (cdc *CDC) closeCanal() {
+	if !cdc.canalClosed {
+		cdc.canal.Close()
+		cdc.canalClosed = true
+	}
+}
+
+func (cdc *CDC) listen(handler *binlogHandler) error {
+	handler.ctx = cdc.canal.Ctx()
+	cdc.canal.SetEventHandler(handler)
+
+	if cdc.Options.Checkpoint != nil {
+		pos, ok, err := cdc.Options.Checkpoint.Load()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return cdc.canal.RunFrom(pos)
+		}
+	}
+
+	switch {
+	case cdc.Options.StartGTID != "":
+		gset, err := gomysql.ParseGTIDSet(cdc.Options.Flavor, cdc.Options.StartGTID)
+		if err != nil {
+			return err
+		}
+		return cdc.canal.StartFromGTID(gset)
+	case cdc.Options.StartFile != "":
+		pos := cdc.Options.StartPos
+		if pos < binlogFirstEventPos {
+			pos = binlogFirstEventPos
+		}
+		return cdc.canal.RunFrom(gomysql.Position{Name: cdc.Options.StartFile, Pos: pos})
+	case !cdc.Options.StartTime.IsZero():
+		return cdc.listenFromTime(cdc.Options.StartTime)
+	}
+
 	coords, err := cdc.canal.GetMasterPos()
 	if err != nil {
 		return err
 	}
-
-	cdc.canal.SetEventHandler(&binlogHandler{cdc: cdc})
 	return cdc.canal.RunFrom(coords)
 }
 
 type binlogHandler struct {
 	cdc                     *CDC
-	canal.DummyEventHandler // Dummy handler from external lib
+	ctx                     context.Context // done when the canal running this handler is closed
+	canal.DummyEventHandler                 // Dummy handler from external lib
+
+	lastSaved time.Time
+	fenced    int32 // set once leadership is lost, a deposed leader must not move the checkpoint
 }
 
 func (h *binlogHandler) String() string {
 	return "binlogHandler"
 }
 
+func (h *binlogHandler) OnPosSynced(pos gomysql.Position, _ gomysql.GTIDSet, force bool) error {
+	checkpoint := h.cdc.Options.Checkpoint
+	if checkpoint == nil || atomic.LoadInt32(&h.fenced) == 1 {
+		return nil
+	}
+	if !force && time.Since(h.lastSaved) < h.cdc.Options.CheckpointInterval {
+		return nil
+	}
+	if err := checkpoint.Save(pos); err != nil {
+		_, _ = fmt.Fprintln(DefaultWriter, "Error: save checkpoint failed ", err.Error())
+		return nil
+	}
+	h.lastSaved = time.Now()
+	return nil
+}
+
 func (h *binlogHandler) OnRow(e *canal.RowsEvent) error {
 	defer func() {
 		if r := recover(); r != nil {
@@ -135,6 +261,10 @@ func (h *binlogHandler) OnRow(e *canal.RowsEvent) error {
 			_, _ = fmt.Fprintln(DefaultWriter, "Error: get row item failed ", err.Error())
 			return nil
 		}
+		// pause and rate limit, give up when the canal is closing, the transaction is replayed from the last position
+		if err := h.cdc.ctl.wait(h.ctx); err != nil {
+			return err
+		}
 		switch e.Action {
 		case canal.UpdateAction:
 			oldItem, err := h.getRowItem(e, i-1)
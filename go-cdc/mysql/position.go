@@ -0,0 +1,184 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gomysql "github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+var (
+	ErrStartOptionsConflict = errors.New("only one of StartGTID, StartFile and StartTime can be set")
+	ErrNoBinaryLogs         = errors.New("no binary logs found on server")
+	ErrScanServerID         = errors.New("ScanServerID must differ from ServerID")
+)
+
+// binlogFirstEventPos is the position of the first event in every binlog file, right after the magic header
+const binlogFirstEventPos = 4
+
+func (o *Options) startModes() int {
+	n := 0
+	if o.StartGTID != "" {
+		n++
+	}
+	if o.StartFile != "" {
+		n++
+	}
+	if !o.StartTime.IsZero() {
+		n++
+	}
+	return n
+}
+
+// PositionAt returns the binlog position of the first transaction written at or after t.
+// If no such transaction exists yet, the current master position is returned.
+// It reads binlogs with Options.ScanServerID, so it can be called while Listen is streaming
+func (cdc *CDC) PositionAt(t time.Time) (gomysql.Position, error) {
+	if cdc.Options.ScanServerID == cdc.Options.ServerID {
+		return gomysql.Position{}, ErrScanServerID
+	}
+	master, err := cdc.canal.GetMasterPos()
+	if err != nil {
+		return gomysql.Position{}, err
+	}
+	files, err := cdc.binaryLogs()
+	if err != nil {
+		return gomysql.Position{}, err
+	}
+	if len(files) == 0 {
+		return gomysql.Position{}, ErrNoBinaryLogs
+	}
+
+	// binary search the last file created at or before t, transactions after t start there or later,
+	// files are created in order so their creation times are sorted
+	target := uint32(t.Unix())
+	lo, hi := 0, len(files) // files[:lo] are created at or before t, files[hi:] after t
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		created, err := cdc.binlogCreatedAt(files[mid])
+		if err != nil {
+			return gomysql.Position{}, err
+		}
+		if created > target {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	start := files[0]
+	if lo > 0 {
+		start = files[lo-1]
+	}
+	return cdc.scanBinlog(gomysql.Position{Name: start, Pos: binlogFirstEventPos}, target, master)
+}
+
+func (cdc *CDC) binaryLogs() ([]string, error) {
+	rr, err := cdc.canal.Execute("SHOW BINARY LOGS")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for i := 0; i < rr.RowNumber(); i++ {
+		name, err := rr.GetString(i, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+func (cdc *CDC) newSyncer() *replication.BinlogSyncer {
+	return replication.NewBinlogSyncer(replication.BinlogSyncerConfig{
+		ServerID: cdc.Options.ScanServerID,
+		Flavor:   cdc.Options.Flavor,
+		Host:     cdc.Options.Host,
+		Port:     uint16(cdc.Options.Port),
+		User:     cdc.Options.User,
+		Password: cdc.Options.Password,
+		// fail fast instead of reconnecting forever during a one-off scan
+		DisableRetrySync: true,
+	})
+}
+
+// binlogCreatedAt returns the timestamp of the format description event which opens the file
+func (cdc *CDC) binlogCreatedAt(file string) (uint32, error) {
+	syncer := cdc.newSyncer()
+	defer syncer.Close()
+	streamer, err := syncer.StartSync(gomysql.Position{Name: file, Pos: binlogFirstEventPos})
+	if err != nil {
+		return 0, err
+	}
+	for {
+		e, err := streamer.GetEvent(context.Background())
+		if err != nil {
+			return 0, err
+		}
+		if e.Header.EventType == replication.FORMAT_DESCRIPTION_EVENT {
+			return e.Header.Timestamp, nil
+		}
+	}
+}
+
+// scanBinlog reads events from pos until a transaction starting at or after target is found,
+// giving up at the master position captured before the scan started
+func (cdc *CDC) scanBinlog(pos gomysql.Position, target uint32, master gomysql.Position) (gomysql.Position, error) {
+	if pos.Compare(master) >= 0 {
+		return master, nil
+	}
+	syncer := cdc.newSyncer()
+	defer syncer.Close()
+	streamer, err := syncer.StartSync(pos)
+	if err != nil {
+		return gomysql.Position{}, err
+	}
+
+	current := pos.Name
+	afterGTID := false
+	for {
+		e, err := streamer.GetEvent(context.Background())
+		if err != nil {
+			return gomysql.Position{}, err
+		}
+		h := e.Header
+		if rotate, ok := e.Event.(*replication.RotateEvent); ok {
+			current = string(rotate.NextLogName)
+			afterGTID = false
+			continue
+		}
+		if h.LogPos == 0 {
+			// artificial events carry no position
+			continue
+		}
+
+		// a transaction starts at its GTID event, or at its first query event when GTIDs are not in use
+		txStart := false
+		switch e.Event.(type) {
+		case *replication.GTIDEvent, *replication.MariadbGTIDEvent:
+			txStart = true
+			afterGTID = true
+		case *replication.QueryEvent:
+			txStart = !afterGTID
+			afterGTID = false
+		default:
+			afterGTID = false
+		}
+		if txStart && h.Timestamp >= target {
+			return gomysql.Position{Name: current, Pos: h.LogPos - h.EventSize}, nil
+		}
+		if (gomysql.Position{Name: current, Pos: h.LogPos}).Compare(master) >= 0 {
+			return master, nil
+		}
+	}
+}
+
+func (cdc *CDC) listenFromTime(t time.Time) error {
+	pos, err := cdc.PositionAt(t)
+	if err != nil {
+		return fmt.Errorf("find binlog position at %s failed: %w", t.Format(time.RFC3339), err)
+	}
+	return cdc.canal.RunFrom(pos)
+}
@@ -0,0 +1,201 @@
+package logger
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	OverflowBlock      = "block"       // 队列满时等待
+	OverflowDropOldest = "drop_oldest" // 队列满时丢弃最早的日志
+	OverflowDropNewest = "drop_newest" // 队列满时丢弃新日志
+)
+
+// Async 异步写入, 日志先进入有界队列, 由后台 goroutine 写入各输出
+type Async struct {
+	BufferSize    int           `mapstructure:"buffer_size"`    // 每个输出队列最多缓存的日志条数, 默认 1024
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // 调用输出 Sync 的间隔, 默认 1s
+	Overflow      string        `mapstructure:"overflow"`       // 队列满时的策略, block, drop_oldest 或 drop_newest, 默认及未知策略均为 block
+}
+
+func (a *Async) dropping() bool {
+	overflow := strings.ToLower(a.Overflow)
+	return overflow == OverflowDropOldest || overflow == OverflowDropNewest
+}
+
+// asyncWriteSyncer queues entries in a ring buffer, a background goroutine writes them in order
+type asyncWriteSyncer struct {
+	ws       zapcore.WriteSyncer
+	overflow string
+	stats    *dropStats
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	idle    *sync.Cond // the queue is empty and nothing is being written
+	ring    [][]byte
+	head    int
+	size    int
+	writing bool
+	closed  bool
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+func newAsyncWriteSyncer(ws zapcore.WriteSyncer, a *Async, stats *dropStats) *asyncWriteSyncer {
+	bufferSize := a.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 1024
+	}
+	interval := a.FlushInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	w := &asyncWriteSyncer{
+		ws:       ws,
+		overflow: strings.ToLower(a.Overflow),
+		stats:    stats,
+		ring:     make([][]byte, bufferSize),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	w.notFull = sync.NewCond(&w.mu)
+	w.idle = sync.NewCond(&w.mu)
+	go w.run(interval)
+	return w
+}
+
+func (w *asyncWriteSyncer) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *asyncWriteSyncer) Write(p []byte) (int, error) {
+	// zap reuses the buffer after Write returns
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return w.ws.Write(entry)
+	}
+	for w.size == len(w.ring) {
+		switch w.overflow {
+		case OverflowDropNewest:
+			w.mu.Unlock()
+			atomic.AddUint64(&w.stats.overflow, 1)
+			return len(p), nil
+		case OverflowDropOldest:
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.size--
+			atomic.AddUint64(&w.stats.overflow, 1)
+		default:
+			w.notify()
+			w.notFull.Wait()
+			if w.closed {
+				w.mu.Unlock()
+				return w.ws.Write(entry)
+			}
+		}
+	}
+	w.ring[(w.head+w.size)%len(w.ring)] = entry
+	w.size++
+	w.mu.Unlock()
+	w.notify()
+	return len(p), nil
+}
+
+// take removes all queued entries
+func (w *asyncWriteSyncer) take() [][]byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	batch := make([][]byte, 0, w.size)
+	for ; w.size > 0; w.size-- {
+		batch = append(batch, w.ring[w.head])
+		w.ring[w.head] = nil
+		w.head = (w.head + 1) % len(w.ring)
+	}
+	w.writing = len(batch) > 0
+	w.notFull.Broadcast()
+	return batch
+}
+
+func (w *asyncWriteSyncer) run(interval time.Duration) {
+	defer close(w.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		flush := false
+		select {
+		case <-w.wake:
+		case <-ticker.C:
+			flush = true
+		}
+		for _, entry := range w.take() {
+			_, _ = w.ws.Write(entry)
+		}
+		if flush {
+			_ = w.ws.Sync()
+		}
+
+		w.mu.Lock()
+		w.writing = false
+		drained := w.size == 0
+		if drained {
+			w.idle.Broadcast()
+		}
+		closed := w.closed
+		w.mu.Unlock()
+		if closed && drained {
+			return
+		}
+	}
+}
+
+// drain waits until the queued entries are written
+func (w *asyncWriteSyncer) drain() {
+	w.mu.Lock()
+	for w.size > 0 || w.writing {
+		w.notify()
+		w.idle.Wait()
+	}
+	w.mu.Unlock()
+}
+
+func (w *asyncWriteSyncer) Sync() error {
+	w.drain()
+	return w.ws.Sync()
+}
+
+// Close drains the queue and stops the background goroutine, later writes go to the output directly
+func (w *asyncWriteSyncer) Close() error {
+	w.drain()
+	w.mu.Lock()
+	w.closed = true
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+	w.notify()
+	<-w.done
+	return nil
+}
+
+// multiCloser closes all closers in order, returns the first error
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var err error
+	for _, c := range m {
+		if closeErr := c.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
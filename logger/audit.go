@@ -0,0 +1,328 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/natefinch/lumberjack"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 审计结果
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+	AuditDenied  = "denied"
+)
+
+var (
+	ErrAuditField  = errors.New("missing mandatory audit field")
+	ErrAuditTamper = errors.New("audit hash chain broken")
+)
+
+// AuditOptions 审计日志, 与应用日志分开输出, 固定为 json 格式, 不采样不丢弃
+type AuditOptions struct {
+	Outputs   []Output `mapstructure:"outputs"`    // 默认 stdout, 忽略各输出的 Level, Json, Encoder 和 BytesPerSecond, file 输出未设置 MaxBackups 和 MaxAge 时不删除滚动文件
+	HashChain bool     `mapstructure:"hash_chain"` // 每行附带 prev_hash 和 hash, 修改, 插入或删除非末尾行后 VerifyAuditFile 校验失败, 无法发现删除末尾行, file 输出不支持 Rotation, 不支持 syslog 输出
+}
+
+// AuditEvent 审计事件, Actor, Action, Target 和 Result 必填
+type AuditEvent struct {
+	Actor  string    // 操作者, 如用户名
+	Action string    // 操作, 如 user.login
+	Target string    // 操作对象
+	Result string    // success, failure 或 denied
+	Time   time.Time // 默认当前时间
+	Fields []Field   // 其他字段, 如客户端 IP
+}
+
+// AuditLogger writes audit events to its own outputs
+type AuditLogger struct {
+	core    zapcore.Core
+	closers []io.Closer
+}
+
+var auditor *AuditLogger
+
+// InitAuditLogger sets the audit logger used by Audit
+func InitAuditLogger(options *AuditOptions) error {
+	a, err := NewAuditLogger(options)
+	if err != nil {
+		return err
+	}
+	auditor = a
+	return nil
+}
+
+// Audit writes event to the audit logger set by InitAuditLogger, events are discarded when it is not set
+func Audit(event AuditEvent) error {
+	if auditor == nil {
+		return nil
+	}
+	return auditor.Log(event)
+}
+
+func CloseAudit() error {
+	if auditor == nil {
+		return nil
+	}
+	return auditor.Close()
+}
+
+func newAuditEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+	}
+}
+
+func NewAuditLogger(options *AuditOptions) (*AuditLogger, error) {
+	outputs := options.Outputs
+	if len(outputs) == 0 {
+		outputs = []Output{{Type: OutputStdout}}
+	}
+	a := &AuditLogger{}
+	var cores []zapcore.Core
+	for i := range outputs {
+		output := &outputs[i]
+		ws, closer, err := output.auditWriteSyncer(options.HashChain)
+		if err != nil {
+			_ = a.Close()
+			return nil, fmt.Errorf("audit %s output: %w", output.Type, err)
+		}
+		if closer != nil {
+			a.closers = append(a.closers, closer)
+		}
+		if options.HashChain {
+			prev, err := lastAuditHash(output)
+			if err != nil {
+				_ = a.Close()
+				return nil, err
+			}
+			ws = &hashChainWriteSyncer{WriteSyncer: ws, prev: prev}
+		}
+		if strings.ToLower(output.Type) == OutputSyslog {
+			// the syslog header carries the time
+			config := newAuditEncoderConfig()
+			config.TimeKey = ""
+			core, err := output.newSyslogCore(zapcore.NewJSONEncoder(config), ws, zap.DebugLevel)
+			if err != nil {
+				_ = a.Close()
+				return nil, fmt.Errorf("audit %s output: %w", output.Type, err)
+			}
+			cores = append(cores, core)
+			continue
+		}
+		cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(newAuditEncoderConfig()), ws, zap.DebugLevel))
+	}
+	a.core = zapcore.NewTee(cores...)
+	return a, nil
+}
+
+// auditWriteSyncer is writeSyncer without the application log retention defaults, a file output only deletes rotated
+// files when MaxBackups or MaxAge are set. With hashChain a file output keeps Filename as its current file, so the chain
+// continues from it after a restart, Rotation would start a new file per period. Syslog outputs are framed by newSyslogCore
+// and not supported with hashChain
+func (o *Output) auditWriteSyncer(hashChain bool) (zapcore.WriteSyncer, io.Closer, error) {
+	if strings.ToLower(o.Type) == OutputSyslog && hashChain {
+		// the chain is computed on JSON lines, the syslog header would break it
+		return nil, nil, errors.New("HashChain is not supported with syslog outputs")
+	}
+	if strings.ToLower(o.Type) != OutputFile {
+		return o.writeSyncer()
+	}
+	if o.Rotation != "" {
+		if hashChain {
+			return nil, nil, errors.New("Rotation is not supported with HashChain, use MaxSize")
+		}
+		return o.writeSyncer()
+	}
+	if o.Filename == "" {
+		return nil, nil, errors.New("missing Filename")
+	}
+	// lumberjack rotates at 100 MB when MaxSize is 0
+	hook := &lumberjack.Logger{
+		Filename:   o.Filename,
+		MaxSize:    o.MaxSize,
+		MaxBackups: o.MaxBackups,
+		MaxAge:     o.MaxAge,
+		Compress:   o.Compress,
+	}
+	return zapcore.AddSync(hook), hook, nil
+}
+
+// Log writes event, an event missing a mandatory field is not written
+func (a *AuditLogger) Log(event AuditEvent) error {
+	for _, field := range []struct{ key, value string }{
+		{"actor", event.Actor}, {"action", event.Action}, {"target", event.Target}, {"result", event.Result},
+	} {
+		if field.value == "" {
+			return fmt.Errorf("%w: %s", ErrAuditField, field.key)
+		}
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	fields := make([]zapcore.Field, 0, 4+len(event.Fields))
+	fields = append(fields,
+		zap.String("actor", event.Actor),
+		zap.String("action", event.Action),
+		zap.String("target", event.Target),
+		zap.String("result", event.Result),
+	)
+	fields = append(fields, event.Fields...)
+	return a.core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Time: event.Time}, fields)
+}
+
+func (a *AuditLogger) Close() error {
+	var err error
+	if a.core != nil {
+		err = a.core.Sync()
+	}
+	for _, closer := range a.closers {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// hashChainWriteSyncer adds "prev_hash" and "hash" to each JSON line, hash is the sha256 of the line up to prev_hash
+type hashChainWriteSyncer struct {
+	zapcore.WriteSyncer
+	mu   sync.Mutex
+	prev string
+}
+
+func (w *hashChainWriteSyncer) Write(p []byte) (int, error) {
+	line := bytes.TrimRight(p, "\n")
+	if len(line) < 2 || line[len(line)-1] != '}' {
+		return 0, fmt.Errorf("audit: not a json line: %q", p)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	body := make([]byte, 0, len(line)+160)
+	body = append(body, line[:len(line)-1]...)
+	if len(line) > 2 {
+		body = append(body, ',')
+	}
+	body = append(body, `"prev_hash":"`+w.prev+`"}`...)
+	hash := auditHash(body)
+
+	out := make([]byte, 0, len(body)+80)
+	out = append(out, body[:len(body)-1]...)
+	out = append(out, `,"hash":"`+hash+"\"}\n"...)
+	if _, err := w.WriteSyncer.Write(out); err != nil {
+		return 0, err
+	}
+	w.prev = hash
+	return len(p), nil
+}
+
+func auditHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+var auditHashSuffix = regexp.MustCompile(`,"hash":"([0-9a-f]{64})"}$`)
+
+type auditChainLink struct {
+	PrevHash *string `json:"prev_hash"`
+}
+
+// verifyAuditLine returns the hash and prev_hash of a hash chained line after checking its hash
+func verifyAuditLine(line []byte) (hash, prev string, err error) {
+	m := auditHashSuffix.FindSubmatchIndex(line)
+	if m == nil {
+		return "", "", errors.New("missing hash")
+	}
+	hash = string(line[m[2]:m[3]])
+	body := append(append([]byte{}, line[:m[0]]...), '}')
+	if auditHash(body) != hash {
+		return "", "", errors.New("hash mismatch")
+	}
+	var link auditChainLink
+	if err := json.Unmarshal(body, &link); err != nil || link.PrevHash == nil {
+		return "", "", errors.New("missing prev_hash")
+	}
+	return hash, *link.PrevHash, nil
+}
+
+// VerifyAuditFile checks the hash chain of an audit file written with HashChain,
+// it returns the prev_hash of the first line, which is the hash of the last line of the previous file after rotation,
+// and ErrAuditTamper with the line number when a line was modified, removed or inserted.
+// Lines removed from the end of the file leave a valid chain and cannot be detected, keep the last hash elsewhere to check them
+func VerifyAuditFile(path string) (first string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	prev := ""
+	for n := 1; scanner.Scan(); n++ {
+		hash, linkPrev, err := verifyAuditLine(scanner.Bytes())
+		if err != nil {
+			return first, fmt.Errorf("%w: line %d: %s", ErrAuditTamper, n, err.Error())
+		}
+		if n == 1 {
+			first = linkPrev
+		} else if linkPrev != prev {
+			return first, fmt.Errorf("%w: line %d: prev_hash does not match line %d", ErrAuditTamper, n, n-1)
+		}
+		prev = hash
+	}
+	return first, scanner.Err()
+}
+
+// lastAuditHash continues the chain of an existing audit file after a restart
+func lastAuditHash(output *Output) (string, error) {
+	if strings.ToLower(output.Type) != OutputFile || output.Filename == "" {
+		return "", nil
+	}
+	f, err := os.Open(output.Filename)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var last []byte
+	for scanner.Scan() {
+		last = append(last[:0], scanner.Bytes()...)
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if len(last) == 0 {
+		return "", nil
+	}
+	hash, _, err := verifyAuditLine(last)
+	if err != nil {
+		return "", fmt.Errorf("%w: last line of %s: %s", ErrAuditTamper, output.Filename, err.Error())
+	}
+	return hash, nil
+}
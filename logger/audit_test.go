@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/natefinch/lumberjack"
+)
+
+func TestAuditRejectsRotationWithHashChain(t *testing.T) {
+	_, err := NewAuditLogger(&AuditOptions{
+		Outputs:   []Output{{Type: OutputFile, Filename: filepath.Join(t.TempDir(), "audit.log"), Rotation: "daily"}},
+		HashChain: true,
+	})
+	if err == nil {
+		t.Fatal("expected an error for Rotation with HashChain")
+	}
+}
+
+func TestAuditSyslogOutput(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	output := Output{Type: OutputSyslog, Network: "udp", Address: conn.LocalAddr().String(), Facility: "auth"}
+
+	if _, err := NewAuditLogger(&AuditOptions{Outputs: []Output{output}, HashChain: true}); err == nil {
+		t.Fatal("expected an error for a syslog output with HashChain")
+	}
+
+	a, err := NewAuditLogger(&AuditOptions{Outputs: []Output{output}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = a.Close()
+	}()
+	if err := a.Log(AuditEvent{Actor: "admin", Action: "user.create", Target: "user:bob", Result: AuditSuccess}); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// auth facility 4, informational severity 6
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<38>1 ") || !strings.Contains(msg, `"actor":"admin"`) {
+		t.Fatalf("not an RFC 5424 message: %q", msg)
+	}
+}
+
+func TestAuditFileRetention(t *testing.T) {
+	_, closer, err := (&Output{Type: OutputFile, Filename: filepath.Join(t.TempDir(), "audit.log")}).auditWriteSyncer(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = closer.Close()
+	}()
+	hook, ok := closer.(*lumberjack.Logger)
+	if !ok {
+		t.Fatalf("closer is %T", closer)
+	}
+	if hook.MaxBackups != 0 || hook.MaxAge != 0 {
+		t.Fatalf("audit files deleted by default: max backups %d, max age %d", hook.MaxBackups, hook.MaxAge)
+	}
+}
+
+func TestAuditChainAcrossRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	options := &AuditOptions{Outputs: []Output{{Type: OutputFile, Filename: path}}, HashChain: true}
+	event := AuditEvent{Actor: "admin", Action: "user.create", Target: "user:bob", Result: AuditSuccess}
+	for i := 0; i < 2; i++ {
+		a, err := NewAuditLogger(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Log(event); err != nil {
+			t.Fatal(err)
+		}
+		_ = a.Close()
+	}
+	if _, err := VerifyAuditFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyAuditFile(filepath.Join(t.TempDir(), "missing.log")); err == nil || errors.Is(err, ErrAuditTamper) {
+		t.Fatalf("missing file: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying l, retrieve it by FromContext
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// WithContext returns a copy of ctx carrying l
+func (l *Logger) WithContext(ctx context.Context) context.Context {
+	return WithContext(ctx, l)
+}
+
+// FromContext returns the logger attached by WithContext, or the global logger if there is none
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return logger
+}
@@ -0,0 +1,227 @@
+package logger
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 编码预设
+const (
+	PresetDevelopment = "development" // 大写带颜色级别, 短调用位置, 可读的耗时
+	PresetProduction  = "production"  // ts 毫秒时间戳, caller 短调用位置
+	PresetECS         = "ecs"         // Elastic Common Schema 字段名
+	PresetGCP         = "gcp"         // Google Cloud Logging 字段名和 severity
+)
+
+// 时间格式, 其他值作为 time.Format 的 layout
+const (
+	TimeISO8601     = "iso8601"
+	TimeRFC3339     = "rfc3339"
+	TimeRFC3339Nano = "rfc3339nano"
+	TimeEpoch       = "epoch"        // 秒, 浮点数
+	TimeEpochMillis = "epoch_millis" // 毫秒, 整数
+	TimeEpochNanos  = "epoch_nanos"  // 纳秒, 整数
+)
+
+// 级别格式
+const (
+	LevelLower = "lower" // debug, info, warn, error ...
+	LevelUpper = "upper" // DEBUG, INFO, WARN, ERROR ...
+	LevelGCP   = "gcp"   // DEBUG, INFO, WARNING, ERROR, CRITICAL, ALERT, EMERGENCY
+)
+
+// 调用位置格式
+const (
+	CallerFull  = "full"  // 完整路径
+	CallerShort = "short" // package/file.go:line
+)
+
+// Encoder 编码设置, 先应用 Preset 再覆盖非空字段, 字段名为 "-" 时不输出该字段
+type Encoder struct {
+	Preset string `mapstructure:"preset"` // development, production, ecs 或 gcp, 默认与之前的固定配置一致
+
+	TimeKey       string `mapstructure:"time_key"`       // 默认 time
+	LevelKey      string `mapstructure:"level_key"`      // 默认 level
+	NameKey       string `mapstructure:"name_key"`       // 默认 logger
+	CallerKey     string `mapstructure:"caller_key"`     // 默认 linenum
+	MessageKey    string `mapstructure:"message_key"`    // 默认 msg
+	StacktraceKey string `mapstructure:"stacktrace_key"` // 默认 stacktrace
+
+	TimeFormat  string `mapstructure:"time_format"`  // 默认 iso8601
+	LevelFormat string `mapstructure:"level_format"` // lower, upper 或 gcp, 默认 lower
+	Caller      string `mapstructure:"caller"`       // full 或 short, 默认 full
+	Color       bool   `mapstructure:"color"`        // console 格式下级别是否带颜色
+}
+
+func newEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "linenum",
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,  // 小写编码器
+		EncodeTime:     zapcore.ISO8601TimeEncoder,     // ISO8601 UTC 时间格式
+		EncodeDuration: zapcore.SecondsDurationEncoder, //
+		EncodeCaller:   zapcore.FullCallerEncoder,      // 全路径编码器
+		EncodeName:     zapcore.FullNameEncoder,
+	}
+}
+
+// preset returns the settings of the preset, an unknown preset has none
+func preset(name string) Encoder {
+	switch strings.ToLower(name) {
+	case PresetDevelopment:
+		return Encoder{LevelFormat: LevelUpper, Caller: CallerShort, Color: true}
+	case PresetProduction:
+		return Encoder{TimeKey: "ts", CallerKey: "caller", TimeFormat: TimeEpochMillis, Caller: CallerShort}
+	case PresetECS:
+		return Encoder{
+			TimeKey:       "@timestamp",
+			LevelKey:      "log.level",
+			NameKey:       "log.logger",
+			CallerKey:     "log.origin.file.name",
+			MessageKey:    "message",
+			StacktraceKey: "error.stack_trace",
+			TimeFormat:    TimeRFC3339Nano,
+			Caller:        CallerShort,
+		}
+	case PresetGCP:
+		return Encoder{
+			TimeKey:       "time",
+			LevelKey:      "severity",
+			CallerKey:     "caller",
+			MessageKey:    "message",
+			StacktraceKey: "stack_trace",
+			TimeFormat:    TimeRFC3339Nano,
+			LevelFormat:   LevelGCP,
+			Caller:        CallerShort,
+		}
+	default:
+		return Encoder{}
+	}
+}
+
+// merge returns the preset of e overridden by the non-empty fields of e
+func (e *Encoder) merge() Encoder {
+	m := preset(e.Preset)
+	override := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	override(&m.TimeKey, e.TimeKey)
+	override(&m.LevelKey, e.LevelKey)
+	override(&m.NameKey, e.NameKey)
+	override(&m.CallerKey, e.CallerKey)
+	override(&m.MessageKey, e.MessageKey)
+	override(&m.StacktraceKey, e.StacktraceKey)
+	override(&m.TimeFormat, e.TimeFormat)
+	override(&m.LevelFormat, e.LevelFormat)
+	override(&m.Caller, e.Caller)
+	m.Color = m.Color || e.Color
+	return m
+}
+
+// config returns the zap encoder config, color only applies to console encoding
+func (e *Encoder) config(json bool) zapcore.EncoderConfig {
+	config := newEncoderConfig()
+	if e == nil {
+		return config
+	}
+	m := e.merge()
+	key := func(dst *string, src string) {
+		switch src {
+		case "":
+		case "-":
+			*dst = ""
+		default:
+			*dst = src
+		}
+	}
+	key(&config.TimeKey, m.TimeKey)
+	key(&config.LevelKey, m.LevelKey)
+	key(&config.NameKey, m.NameKey)
+	key(&config.CallerKey, m.CallerKey)
+	key(&config.MessageKey, m.MessageKey)
+	key(&config.StacktraceKey, m.StacktraceKey)
+
+	if m.TimeFormat != "" {
+		config.EncodeTime = timeEncoder(m.TimeFormat)
+	}
+	config.EncodeLevel = levelEncoder(m.LevelFormat, m.Color && !json)
+	if strings.ToLower(m.Caller) == CallerShort {
+		config.EncodeCaller = zapcore.ShortCallerEncoder
+	}
+	if strings.ToLower(e.Preset) == PresetDevelopment {
+		config.EncodeDuration = zapcore.StringDurationEncoder
+	}
+	return config
+}
+
+func timeEncoder(format string) zapcore.TimeEncoder {
+	switch strings.ToLower(format) {
+	case TimeISO8601:
+		return zapcore.ISO8601TimeEncoder
+	case TimeRFC3339:
+		return zapcore.RFC3339TimeEncoder
+	case TimeRFC3339Nano:
+		return zapcore.RFC3339NanoTimeEncoder
+	case TimeEpoch:
+		return zapcore.EpochTimeEncoder
+	case TimeEpochMillis:
+		// zapcore.EpochMillisTimeEncoder appends a float
+		return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendInt64(t.UnixNano() / int64(time.Millisecond))
+		}
+	case TimeEpochNanos:
+		return zapcore.EpochNanosTimeEncoder
+	default:
+		return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(t.Format(format))
+		}
+	}
+}
+
+func levelEncoder(format string, color bool) zapcore.LevelEncoder {
+	switch strings.ToLower(format) {
+	case LevelUpper:
+		if color {
+			return zapcore.CapitalColorLevelEncoder
+		}
+		return zapcore.CapitalLevelEncoder
+	case LevelGCP:
+		return gcpLevelEncoder
+	default:
+		if color {
+			return zapcore.LowercaseColorLevelEncoder
+		}
+		return zapcore.LowercaseLevelEncoder
+	}
+}
+
+// gcpLevelEncoder encodes levels as the severities of Google Cloud Logging
+func gcpLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch level {
+	case zapcore.DebugLevel:
+		enc.AppendString("DEBUG")
+	case zapcore.InfoLevel:
+		enc.AppendString("INFO")
+	case zapcore.WarnLevel:
+		enc.AppendString("WARNING")
+	case zapcore.ErrorLevel:
+		enc.AppendString("ERROR")
+	case zapcore.DPanicLevel:
+		enc.AppendString("CRITICAL")
+	case zapcore.PanicLevel:
+		enc.AppendString("ALERT")
+	case zapcore.FatalLevel:
+		enc.AppendString("EMERGENCY")
+	default:
+		enc.AppendString("DEFAULT")
+	}
+}
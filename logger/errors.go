@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxErrorChain bounds the unwrapped errors recorded, in case of a cyclic Unwrap
+const maxErrorChain = 32
+
+// Err adds err under the key "error" as an object with its message, type, unwrapped chain and stack trace
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr adds err under key like Err, a nil err is skipped
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object(key, errorObject{err: err})
+}
+
+// errorObject encodes as {"message", "type", "chain": [{"message", "type"}], "causes": [...], "stack"}
+type errorObject struct {
+	err error
+}
+
+func (e errorObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("message", errorMessage(e.err))
+	enc.AddString("type", fmt.Sprintf("%T", e.err))
+	if nilPointer(e.err) {
+		return nil
+	}
+
+	stack := errorStack(e.err)
+	var chain errorChain
+	cause := e.err
+	for i := 0; i < maxErrorChain; i++ {
+		// errors joined by errors.Join or fmt.Errorf with several %w
+		if multi, ok := cause.(interface{ Unwrap() []error }); ok {
+			var causes errorCauses
+			for _, err := range multi.Unwrap() {
+				if err != nil {
+					causes = append(causes, errorObject{err: err})
+				}
+			}
+			if err := enc.AddArray("causes", causes); err != nil {
+				return err
+			}
+			break
+		}
+		if cause = errors.Unwrap(cause); cause == nil {
+			break
+		}
+		chain = append(chain, cause)
+		if nilPointer(cause) {
+			break
+		}
+		// the innermost stack is the closest to where the error happened
+		if s := errorStack(cause); s != "" {
+			stack = s
+		}
+	}
+	if len(chain) != 0 {
+		if err := enc.AddArray("chain", chain); err != nil {
+			return err
+		}
+	}
+	if stack != "" {
+		enc.AddString("stack", stack)
+	}
+	return nil
+}
+
+type errorChain []error
+
+func (c errorChain) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, err := range c {
+		if err := enc.AppendObject(errorLink{err: err}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errorLink is one unwrapped error of the chain
+type errorLink struct {
+	err error
+}
+
+func (e errorLink) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("message", errorMessage(e.err))
+	enc.AddString("type", fmt.Sprintf("%T", e.err))
+	return nil
+}
+
+type errorCauses []errorObject
+
+func (c errorCauses) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, cause := range c {
+		if err := enc.AppendObject(cause); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errorStack returns the stack trace carried by err, such as the StackTrace method of github.com/pkg/errors
+func errorStack(err error) string {
+	if nilPointer(err) {
+		return ""
+	}
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ""
+	}
+	stack := strings.TrimPrefix(fmt.Sprintf("%+v", method.Call(nil)[0].Interface()), "\n")
+	if stack == "[]" {
+		return ""
+	}
+	return stack
+}
+
+// nilPointer reports a typed nil such as a nil *MyError held by an error, which passes err == nil
+func nilPointer(err error) bool {
+	v := reflect.ValueOf(err)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// errorMessage returns "<nil>" when Error panics on a typed nil, like zap.Error
+func errorMessage(err error) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if !nilPointer(err) {
+				panic(r)
+			}
+			msg = "<nil>"
+		}
+	}()
+	return err.Error()
+}
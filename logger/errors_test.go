@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type nilReceiverError struct{}
+
+func (e *nilReceiverError) Error() string {
+	return "unreachable " + fmt.Sprint(*e)
+}
+
+func TestErrTypedNil(t *testing.T) {
+	buf := &MemoryBuffer{}
+	l := GetLoggerByOptions(&Options{Outputs: []Output{{Type: OutputMemory, Json: true, Buffer: buf}}})
+	var p *nilReceiverError
+	var err error = p
+	l.WithFields(Err(err)).Info("typed nil")
+	l.WithFields(Err(fmt.Errorf("wrap: %w", err))).Info("wrapped typed nil")
+	lines := buf.Lines()
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines: %q", len(lines), lines)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, `"message":"<nil>"`) {
+			t.Errorf("missing <nil> message: %s", line)
+		}
+	}
+}
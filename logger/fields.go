@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Field is a typed key and value, encoded without reflection
+type Field = zap.Field
+
+func String(key string, value string) Field {
+	return zap.String(key, value)
+}
+
+func Int(key string, value int) Field {
+	return zap.Int(key, value)
+}
+
+func Int64(key string, value int64) Field {
+	return zap.Int64(key, value)
+}
+
+func Uint(key string, value uint) Field {
+	return zap.Uint(key, value)
+}
+
+func Float64(key string, value float64) Field {
+	return zap.Float64(key, value)
+}
+
+func Bool(key string, value bool) Field {
+	return zap.Bool(key, value)
+}
+
+func Duration(key string, value time.Duration) Field {
+	return zap.Duration(key, value)
+}
+
+func Time(key string, value time.Time) Field {
+	return zap.Time(key, value)
+}
+
+// Any falls back to reflection for values without a typed constructor
+func Any(key string, value interface{}) Field {
+	return zap.Any(key, value)
+}
+
+func WithFields(fields ...Field) *Logger {
+	return logger.WithFields(fields...)
+}
+
+// WithFields returns a child logger with typed fields, they are encoded once here instead of on every log call.
+// Building the child costs more than one log call, keep it for long-lived loggers and pass per-call fields to Infow and the like
+func (l *Logger) WithFields(fields ...Field) *Logger {
+	ln := l.clone()
+	ln.setZap(l.logger.Desugar().With(fields...).Sugar())
+	return ln
+}
+
+// sweeten appends fields to the args of WithField for the sugared logger
+func (l *Logger) sweeten(fields []Field) []interface{} {
+	args := make([]interface{}, 0, len(l.args)+len(fields))
+	args = append(args, l.args...)
+	for _, field := range fields {
+		args = append(args, field)
+	}
+	return args
+}
+
+// Typed field methods, fields are passed to zap as they are without reflection or per-call child loggers.
+// Each method calls zap directly so the caller frame skipped stays the same
+
+func Debugw(msg string, fields ...Field) {
+	std.Debugw(msg, fields...)
+}
+
+func (l *Logger) Debugw(msg string, fields ...Field) {
+	if len(l.args) != 0 {
+		l.logger.Debugw(msg, l.sweeten(fields)...)
+		return
+	}
+	l.fast.Debug(msg, fields...)
+}
+
+func Infow(msg string, fields ...Field) {
+	std.Infow(msg, fields...)
+}
+
+func (l *Logger) Infow(msg string, fields ...Field) {
+	if len(l.args) != 0 {
+		l.logger.Infow(msg, l.sweeten(fields)...)
+		return
+	}
+	l.fast.Info(msg, fields...)
+}
+
+func Warnw(msg string, fields ...Field) {
+	std.Warnw(msg, fields...)
+}
+
+func (l *Logger) Warnw(msg string, fields ...Field) {
+	if len(l.args) != 0 {
+		l.logger.Warnw(msg, l.sweeten(fields)...)
+		return
+	}
+	l.fast.Warn(msg, fields...)
+}
+
+func Errorw(msg string, fields ...Field) {
+	std.Errorw(msg, fields...)
+}
+
+func (l *Logger) Errorw(msg string, fields ...Field) {
+	if l.span != nil {
+		l.addSpanEvent(zap.ErrorLevel, msg)
+	}
+	if len(l.args) != 0 {
+		l.logger.Errorw(msg, l.sweeten(fields)...)
+		return
+	}
+	l.fast.Error(msg, fields...)
+}
+
+func Panicw(msg string, fields ...Field) {
+	std.Panicw(msg, fields...)
+}
+
+func (l *Logger) Panicw(msg string, fields ...Field) {
+	if l.span != nil {
+		l.addSpanEvent(zap.PanicLevel, msg)
+	}
+	if len(l.args) != 0 {
+		l.logger.Panicw(msg, l.sweeten(fields)...)
+		return
+	}
+	l.fast.Panic(msg, fields...)
+}
+
+func Fatalw(msg string, fields ...Field) {
+	std.Fatalw(msg, fields...)
+}
+
+func (l *Logger) Fatalw(msg string, fields ...Field) {
+	if l.span != nil {
+		l.addSpanEvent(zap.FatalLevel, msg)
+	}
+	if len(l.args) != 0 {
+		l.logger.Fatalw(msg, l.sweeten(fields)...)
+		return
+	}
+	l.fast.Fatal(msg, fields...)
+}
@@ -0,0 +1,22 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInfow(t *testing.T) {
+	tl := NewTestLogger(t)
+	tl.Infow("typed", String("user", "admin"), Int("attempt", 3))
+	tl.WithField("req_id", "r1").Warnw("typed with args", Int("attempt", 4))
+
+	tl.AssertField("typed", "user", "admin")
+	tl.AssertField("typed", "attempt", 3)
+	tl.AssertField("typed with args", "req_id", "r1")
+	tl.AssertField("typed with args", "attempt", 4)
+	for _, entry := range tl.Entries() {
+		if !strings.HasSuffix(entry.Caller.File, "fields_test.go") {
+			t.Errorf("%q caller %s, want fields_test.go", entry.Message, entry.Caller.File)
+		}
+	}
+}
@@ -2,37 +2,65 @@ package logger
 
 import (
 	"fmt"
-	"github.com/natefinch/lumberjack"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"os"
 	"strings"
+	"time"
 )
 
 var logger *Logger
 
+// std is the global logger skipping one more caller frame, for the package level log functions
+var std *Logger
+
 type Logger struct {
 	logger *zap.SugaredLogger
-	hooks  *lumberjack.Logger
+	fast   *zap.Logger // logger desugared, for the typed field methods, set together with logger by setZap
+	root   *reloadRoot // current outputs, replaced by Reload
+	level  zap.AtomicLevel
+	caller bool
 	args   []interface{}
+	// set by Ctx when SpanEvents is enabled and ctx carries a recording span
+	spanEvents bool
+	span       trace.Span
 }
 
 type Options struct {
-	Level      string
-	Json       bool   // 是否 json 格式
-	Filename   string //日志文件位置
-	MaxSize    int    // 单文件最大容量,单位是MB
-	MaxBackups int    // 最大保留过期文件个数
-	MaxAge     int    // 保留过期文件的最大时间间隔,单位是天
-	Compress   bool   // 是否需要压缩滚动日志, 使用的 gzip 压缩
+	Level      string `mapstructure:"level"`
+	Json       bool   `mapstructure:"json"`        // 是否 json 格式
+	Filename   string `mapstructure:"filename"`    //日志文件位置
+	MaxSize    int    `mapstructure:"max_size"`    // 单文件最大容量,单位是MB
+	MaxBackups int    `mapstructure:"max_backups"` // 最大保留过期文件个数
+	MaxAge     int    `mapstructure:"max_age"`     // 保留过期文件的最大时间间隔,单位是天
+	Compress   bool   `mapstructure:"compress"`    // 是否需要压缩滚动日志, 使用的 gzip 压缩
+
+	Outputs []Output `mapstructure:"outputs"` // 多个输出, 各自的级别和格式, 设置后忽略 Json 和 Filename 等单文件配置
+
+	Caller     bool   `mapstructure:"caller"`     // 是否输出调用位置
+	Stacktrace string `mapstructure:"stacktrace"` // 输出堆栈的最低级别, 默认不输出
+
+	Encoder *Encoder `mapstructure:"encoder"` // 编码设置, 时间格式, 级别格式, 字段名等, 默认 ISO8601 时间, 小写级别, linenum 全路径调用位置
+
+	SpanEvents bool `mapstructure:"span_events"` // 通过 Ctx 得到的 Logger 将 error 及以上级别日志记录为 span 事件
+
+	Redaction          *Redaction    `mapstructure:"redaction"`            // 脱敏, 默认不脱敏, 可使用 DefaultRedaction()
+	Async              *Async        `mapstructure:"async"`                // 异步写入, 默认同步
+	Sampling           *Sampling     `mapstructure:"sampling"`             // 采样, 默认不采样
+	DropReportInterval time.Duration `mapstructure:"drop_report_interval"` // 输出被采样, 限速或异步队列溢出丢弃条数的间隔, 默认 1 分钟, 该统计日志同样受采样和限速影响
 }
 
 func InitLogger() {
-	logger = GetLogger()
+	setLogger(GetLogger())
 }
 
 func InitLoggerByOptions(options *Options) {
-	logger = GetLoggerByOptions(options)
+	setLogger(GetLoggerByOptions(options))
+}
+
+func setLogger(l *Logger) {
+	logger = l
+	std = l.withCallerSkip(1)
 }
 
 func Close() error {
@@ -45,132 +73,165 @@ func GetLogger() *Logger {
 
 func GetLoggerByOptions(options *Options) *Logger {
 	logger := &Logger{}
-	logger.hooks = options.getLumberjack()
 	logger.initLogger(options)
 	return logger
 }
 
+// Close flushes and closes the outputs, entries logged afterwards are dropped
 func (l *Logger) Close() error {
-	if l.hooks != nil {
-		if err := l.hooks.Close(); err != nil {
-			return err
-		}
-	}
-	if err := l.logger.Sync(); err != nil {
-		return err
+	if l.root == nil {
+		return l.logger.Sync()
 	}
-	return nil
+	return l.root.load().close()
 }
 
 func (l *Logger) initLogger(options *Options) {
 	// 设置日志级别
 	atomicLevel := zap.NewAtomicLevel()
-	switch strings.ToLower(options.Level) {
+	level, err := parseLevel(options.Level)
+	if err != nil {
+		level = zap.DebugLevel
+	}
+	atomicLevel.SetLevel(level)
+	l.level = atomicLevel
+	l.spanEvents = options.SpanEvents
+	// skip the Logger wrapper methods when reporting caller and stacktrace
+	zapOptions := []zap.Option{zap.AddCallerSkip(1)}
+	if options.Caller {
+		zapOptions = append(zapOptions, zap.AddCaller())
+		l.caller = true
+	}
+	if options.Stacktrace != "" {
+		if stackLevel, err := parseLevel(options.Stacktrace); err == nil {
+			zapOptions = append(zapOptions, zap.AddStacktrace(stackLevel))
+		}
+	}
+	l.root = &reloadRoot{}
+	l.setZap(zap.New(&reloadCore{root: l.root}, zapOptions...).Sugar())
+	// 输出
+	l.root.current.Store(l.newGeneration(options))
+}
+
+// outputs converts the single file options to outputs when Outputs is not set
+func (o *Options) outputs() []Output {
+	if len(o.Outputs) != 0 {
+		return o.Outputs
+	}
+	outputs := []Output{{Type: OutputStdout, Json: o.Json}}
+	if o.Filename != "" {
+		outputs = append(outputs, Output{
+			Type:       OutputFile,
+			Json:       o.Json,
+			Filename:   o.Filename,
+			MaxSize:    o.MaxSize,
+			MaxBackups: o.MaxBackups,
+			MaxAge:     o.MaxAge,
+			Compress:   o.Compress,
+		})
+	}
+	return outputs
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(level) {
 	case "fatal":
-		atomicLevel.SetLevel(zap.FatalLevel)
+		return zap.FatalLevel, nil
 	case "panic":
-		atomicLevel.SetLevel(zap.PanicLevel)
+		return zap.PanicLevel, nil
 	case "error":
-		atomicLevel.SetLevel(zap.ErrorLevel)
+		return zap.ErrorLevel, nil
 	case "warn":
-		atomicLevel.SetLevel(zap.WarnLevel)
+		return zap.WarnLevel, nil
 	case "info":
-		atomicLevel.SetLevel(zap.InfoLevel)
+		return zap.InfoLevel, nil
 	case "debug":
-		atomicLevel.SetLevel(zap.DebugLevel)
+		return zap.DebugLevel, nil
 	default:
-		atomicLevel.SetLevel(zap.DebugLevel)
-	}
-	// 公用编码器
-	encoderConfig := zapcore.EncoderConfig{
-		TimeKey:        "time",
-		LevelKey:       "level",
-		NameKey:        "logger",
-		CallerKey:      "linenum",
-		MessageKey:     "msg",
-		StacktraceKey:  "stacktrace",
-		LineEnding:     zapcore.DefaultLineEnding,
-		EncodeLevel:    zapcore.LowercaseLevelEncoder,  // 小写编码器
-		EncodeTime:     zapcore.ISO8601TimeEncoder,     // ISO8601 UTC 时间格式
-		EncodeDuration: zapcore.SecondsDurationEncoder, //
-		EncodeCaller:   zapcore.FullCallerEncoder,      // 全路径编码器
-		EncodeName:     zapcore.FullNameEncoder,
-	}
-	var encoder zapcore.Encoder
-	if options.Json {
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
-	} else {
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	}
-	// 打印
-	var writeSyncer zapcore.WriteSyncer
-	if l.hooks != nil {
-		writeSyncer = zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), zapcore.AddSync(l.hooks))
-	} else {
-		writeSyncer = zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout))
+		return zap.DebugLevel, fmt.Errorf("unknown log level %q", level)
 	}
+}
 
-	core := zapcore.NewCore(
-		encoder,     // 编码器配置
-		writeSyncer, // 打印
-		atomicLevel, // 日志级别
-	)
-	l.logger = zap.New(core).Sugar()
+func SetLevel(level string) error {
+	return logger.SetLevel(level)
 }
 
-func (o *Options) getLumberjack() *lumberjack.Logger {
-	filename := o.Filename
-	maxSize := o.MaxSize
-	maxBackup := o.MaxBackups
-	maxAge := o.MaxAge
-	compress := o.Compress
+func GetLevel() string {
+	return logger.GetLevel()
+}
 
-	if filename == "" {
-		return nil
-	}
-	if maxSize == 0 {
-		maxSize = 1
-	}
-	if maxBackup == 0 {
-		maxBackup = 10
-	}
-	if maxAge == 0 {
-		maxAge = 10
+// SetLevel changes the level at runtime, it applies to all loggers derived by WithField
+func (l *Logger) SetLevel(level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
 	}
+	l.level.SetLevel(lvl)
+	return nil
+}
+
+func (l *Logger) GetLevel() string {
+	return l.level.Level().String()
+}
 
-	return &lumberjack.Logger{
-		Filename:   filename,
-		MaxSize:    maxSize,
-		MaxBackups: maxBackup,
-		MaxAge:     maxAge,
-		Compress:   compress,
+func (l *Logger) clone() *Logger {
+	return &Logger{
+		logger: l.logger,
+		fast:   l.fast,
+		root:   l.root,
+		level:  l.level,
+		caller: l.caller,
+		args:   l.args,
+
+		spanEvents: l.spanEvents,
+		span:       l.span,
 	}
 }
 
+// setZap sets the sugared logger and its desugared copy used by the typed field methods
+func (l *Logger) setZap(logger *zap.SugaredLogger) {
+	l.logger = logger
+	l.fast = logger.Desugar()
+}
+
+func (l *Logger) withCallerSkip(skip int) *Logger {
+	ln := l.clone()
+	ln.setZap(l.logger.Desugar().WithOptions(zap.AddCallerSkip(skip)).Sugar())
+	return ln
+}
+
+func Named(name string) *Logger {
+	return logger.Named(name)
+}
+
+// Named returns a child logger, names are joined by "." like "app.db"
+func (l *Logger) Named(name string) *Logger {
+	ln := l.clone()
+	ln.setZap(l.logger.Named(name))
+	return ln
+}
+
 func WithField(key string, value interface{}) *Logger {
 	return logger.WithField(key, value)
 }
 
 // WithField set key and value for logger msg
 func (l *Logger) WithField(key string, value interface{}) *Logger {
-	ln := Logger{
-		logger: l.logger,
-		hooks:  l.hooks,
-		args:   l.args,
-	}
-	ln.args = append(ln.args, key)
-	ln.args = append(ln.args, value)
-	return &ln
+	ln := l.clone()
+	// copy args, sibling loggers must not share the backing array
+	ln.args = make([]interface{}, len(l.args), len(l.args)+2)
+	copy(ln.args, l.args)
+	ln.args = append(ln.args, key, value)
+	return ln
 }
 
 // Info
 
 func Info(args ...interface{}) {
-	logger.Info(args...)
+	std.Info(args...)
 }
 
 func Infof(template string, args ...interface{}) {
-	logger.Infof(template, args...)
+	std.Infof(template, args...)
 }
 
 func (l *Logger) Info(args ...interface{}) {
@@ -192,14 +253,17 @@ func (l *Logger) Infof(template string, args ...interface{}) {
 // Error
 
 func Error(args ...interface{}) {
-	logger.Error(args...)
+	std.Error(args...)
 }
 
 func Errorf(template string, args ...interface{}) {
-	logger.Errorf(template, args...)
+	std.Errorf(template, args...)
 }
 
 func (l *Logger) Error(args ...interface{}) {
+	if l.span != nil {
+		l.addSpanEvent(zap.ErrorLevel, fmt.Sprint(args...))
+	}
 	if len(l.args) != 0 {
 		l.logger.Errorw(fmt.Sprint(args...), l.args...)
 		return
@@ -208,6 +272,9 @@ func (l *Logger) Error(args ...interface{}) {
 }
 
 func (l *Logger) Errorf(template string, args ...interface{}) {
+	if l.span != nil {
+		l.addSpanEvent(zap.ErrorLevel, fmt.Sprintf(template, args...))
+	}
 	if len(l.args) != 0 {
 		l.logger.Errorw(fmt.Sprintf(template, args...), l.args...)
 		return
@@ -218,11 +285,11 @@ func (l *Logger) Errorf(template string, args ...interface{}) {
 // Debug
 
 func Debug(args ...interface{}) {
-	logger.Debug(args...)
+	std.Debug(args...)
 }
 
 func Debugf(template string, args ...interface{}) {
-	logger.Debugf(template, args...)
+	std.Debugf(template, args...)
 }
 
 func (l *Logger) Debug(args ...interface{}) {
@@ -244,11 +311,11 @@ func (l *Logger) Debugf(template string, args ...interface{}) {
 // Warn
 
 func Warn(args ...interface{}) {
-	logger.Warn(args...)
+	std.Warn(args...)
 }
 
 func Warnf(template string, args ...interface{}) {
-	logger.Warnf(template, args...)
+	std.Warnf(template, args...)
 }
 
 func (l *Logger) Warn(args ...interface{}) {
@@ -270,14 +337,17 @@ func (l *Logger) Warnf(template string, args ...interface{}) {
 // Fatal
 
 func Fatal(args ...interface{}) {
-	logger.Fatal(args...)
+	std.Fatal(args...)
 }
 
 func Fatalf(template string, args ...interface{}) {
-	logger.Fatalf(template, args...)
+	std.Fatalf(template, args...)
 }
 
 func (l *Logger) Fatal(args ...interface{}) {
+	if l.span != nil {
+		l.addSpanEvent(zap.FatalLevel, fmt.Sprint(args...))
+	}
 	if len(l.args) != 0 {
 		l.logger.Fatalw(fmt.Sprint(args...), l.args...)
 		return
@@ -286,6 +356,9 @@ func (l *Logger) Fatal(args ...interface{}) {
 }
 
 func (l *Logger) Fatalf(template string, args ...interface{}) {
+	if l.span != nil {
+		l.addSpanEvent(zap.FatalLevel, fmt.Sprintf(template, args...))
+	}
 	if len(l.args) != 0 {
 		l.logger.Fatalw(fmt.Sprintf(template, args...), l.args...)
 		return
@@ -296,14 +369,17 @@ func (l *Logger) Fatalf(template string, args ...interface{}) {
 // Panic
 
 func Panic(args ...interface{}) {
-	logger.Panic(args...)
+	std.Panic(args...)
 }
 
 func Panicf(template string, args ...interface{}) {
-	logger.Panicf(template, args...)
+	std.Panicf(template, args...)
 }
 
 func (l *Logger) Panic(args ...interface{}) {
+	if l.span != nil {
+		l.addSpanEvent(zap.PanicLevel, fmt.Sprint(args...))
+	}
 	if len(l.args) != 0 {
 		l.logger.Panicw(fmt.Sprint(args...), l.args...)
 		return
@@ -312,6 +388,9 @@ func (l *Logger) Panic(args ...interface{}) {
 }
 
 func (l *Logger) Panicf(template string, args ...interface{}) {
+	if l.span != nil {
+		l.addSpanEvent(zap.PanicLevel, fmt.Sprintf(template, args...))
+	}
 	if len(l.args) != 0 {
 		l.logger.Panicw(fmt.Sprintf(template, args...), l.args...)
 		return
@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestLogAfterClose(t *testing.T) {
+	for name, options := range map[string]*Options{
+		"sync":  {Level: "debug"},
+		"async": {Level: "debug", Async: &Async{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := GetLoggerByOptions(options)
+			ln := l.WithField("k", "v")
+			// syncing stdout may fail when it is a pipe
+			_ = l.Close()
+			l.Info("after close")
+			ln.Errorf("after close %d", 1)
+		})
+	}
+}
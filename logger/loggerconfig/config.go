@@ -0,0 +1,42 @@
+// Package loggerconfig initializes the global logger from the config read by cmd-config,
+// kept apart so the logger does not depend on cmd-config and viper
+package loggerconfig
+
+import (
+	"fmt"
+	"os"
+
+	cmdconfig "github.com/PengShaw/go-common/cmd-config"
+	"github.com/PengShaw/go-common/logger"
+)
+
+// ConfigKey is the section of the config file holding logger.Options
+const ConfigKey = "logger"
+
+// InitLogger initializes the global logger by the logger section of the config read by cmdconfig.GetConfig,
+// level and outputs are rebuilt in place by logger.Reload when the config file changes
+func InitLogger() error {
+	options, err := GetOptions()
+	if err != nil {
+		return err
+	}
+	logger.InitLoggerByOptions(options)
+	cmdconfig.OnConfigChange(func() {
+		options, err := GetOptions()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "logger: reload config: %s\n", err.Error())
+			return
+		}
+		logger.Reload(options)
+	})
+	return nil
+}
+
+// GetOptions parses the logger section of the config read by cmdconfig.GetConfig
+func GetOptions() (*logger.Options, error) {
+	options := &logger.Options{}
+	if err := cmdconfig.GetConfigKey(ConfigKey, options); err != nil {
+		return nil, fmt.Errorf("parse %s config: %w", ConfigKey, err)
+	}
+	return options, nil
+}
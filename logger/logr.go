@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+)
+
+// logrSink writes logr entries through a Logger, V(0) is logged at info level and V(1) and above at debug level
+type logrSink struct {
+	logger *zap.SugaredLogger
+}
+
+func Logr() logr.Logger {
+	return logger.Logr()
+}
+
+// Logr returns a logr.Logger backed by l
+func (l *Logger) Logr() logr.Logger {
+	return logr.New(&logrSink{logger: l.logger.With(l.args...)})
+}
+
+func (s *logrSink) Init(info logr.RuntimeInfo) {
+	// skip the frames of logr.Logger in addition to this sink
+	s.logger = s.logger.Desugar().WithOptions(zap.AddCallerSkip(info.CallDepth)).Sugar()
+}
+
+func (s *logrSink) Enabled(level int) bool {
+	if level > 0 {
+		return s.logger.Desugar().Core().Enabled(zap.DebugLevel)
+	}
+	return s.logger.Desugar().Core().Enabled(zap.InfoLevel)
+}
+
+func (s *logrSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	if level > 0 {
+		s.logger.Debugw(msg, keysAndValues...)
+		return
+	}
+	s.logger.Infow(msg, keysAndValues...)
+}
+
+func (s *logrSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.logger.With(zap.Error(err)).Errorw(msg, keysAndValues...)
+}
+
+func (s *logrSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &logrSink{logger: s.logger.With(keysAndValues...)}
+}
+
+func (s *logrSink) WithName(name string) logr.LogSink {
+	return &logrSink{logger: s.logger.Named(name)}
+}
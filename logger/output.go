@@ -0,0 +1,293 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/natefinch/lumberjack"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"   // lumberjack 滚动文件
+	OutputSocket = "socket" // unix, unixgram, udp 或 tcp socket, 每条日志一次写入
+	OutputMemory = "memory" // 内存, 写入 Buffer
+	OutputSyslog = "syslog" // RFC 5424 syslog, 默认 unixgram /dev/log, 级别映射为 syslog severity
+)
+
+type Output struct {
+	Type  string `mapstructure:"type"`
+	Level string `mapstructure:"level"` // 该输出的最低级别, 默认不限制, 仍受 Logger 级别控制
+	Json  bool   `mapstructure:"json"`  // 是否 json 格式
+	Color bool   `mapstructure:"color"` // console 格式下级别是否带颜色
+
+	Encoder *Encoder `mapstructure:"encoder"` // 该输出的编码设置, 默认使用 Options.Encoder
+
+	BytesPerSecond int `mapstructure:"bytes_per_second"` // 每秒最多写入字节数, 超出的日志丢弃, 单条超过该值的日志在剩余整秒额度时写入, 默认不限制
+
+	// file
+	Filename   string `mapstructure:"filename"`    //日志文件位置
+	MaxSize    int    `mapstructure:"max_size"`    // 单文件最大容量,单位是MB
+	MaxBackups int    `mapstructure:"max_backups"` // 最大保留过期文件个数
+	MaxAge     int    `mapstructure:"max_age"`     // 保留过期文件的最大时间间隔,单位是天
+	Compress   bool   `mapstructure:"compress"`    // 是否需要压缩滚动日志, 使用的 gzip 压缩
+
+	// file 按时间滚动, 设置 Rotation 后代替 lumberjack, MaxSize 为 0 时不按大小滚动, MaxBackups 和 MaxAge 为 0 时不清理
+	Rotation string                  `mapstructure:"rotation"` // daily, hourly 或 size, daily 和 hourly 同时按 MaxSize 滚动, 先到先滚
+	Pattern  string                  `mapstructure:"pattern"`  // 文件名模板, 支持 %Y %m %d %H %M 和同一周期内的序号 %i, 如 "./logs/app-%Y-%m-%d.log", 默认由 Filename 生成
+	Symlink  string                  `mapstructure:"symlink"`  // 指向当前文件的软链接
+	UTC      bool                    `mapstructure:"utc"`      // 文件名使用 UTC 时间, 默认本地时间
+	OnRotate func(closedFile string) `mapstructure:"-"`        // 文件滚动关闭后在新 goroutine 中回调, 可用于上传
+
+	// socket, syslog
+	Network string `mapstructure:"network"` // unix, unixgram, udp, tcp
+	Address string `mapstructure:"address"`
+
+	// syslog
+	Facility string `mapstructure:"facility"` // user, daemon, local0 ~ local7 等, 默认 user
+	AppName  string `mapstructure:"app_name"` // 默认程序名
+
+	// memory
+	Buffer *MemoryBuffer `mapstructure:"-"`
+}
+
+func (o *Output) encoder(defaultEncoder *Encoder) zapcore.Encoder {
+	e := o.Encoder
+	if e == nil {
+		e = defaultEncoder
+	}
+	if o.Color {
+		colored := Encoder{Color: true}
+		if e != nil {
+			colored = *e
+			colored.Color = true
+		}
+		e = &colored
+	}
+	encoderConfig := e.config(o.Json)
+	if strings.ToLower(o.Type) == OutputSyslog && o.Encoder == nil {
+		// the syslog header carries the time
+		encoderConfig.TimeKey = ""
+	}
+	if o.Json {
+		return zapcore.NewJSONEncoder(encoderConfig)
+	}
+	return zapcore.NewConsoleEncoder(encoderConfig)
+}
+
+// levelEnabler enables levels passing both the logger level and the output level
+func (o *Output) levelEnabler(loggerLevel zap.AtomicLevel) (zapcore.LevelEnabler, error) {
+	if o.Level == "" {
+		return loggerLevel, nil
+	}
+	outputLevel, err := parseLevel(o.Level)
+	if err != nil {
+		return nil, err
+	}
+	return zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return loggerLevel.Enabled(lvl) && outputLevel.Enabled(lvl)
+	}), nil
+}
+
+func (o *Output) writeSyncer() (zapcore.WriteSyncer, io.Closer, error) {
+	switch strings.ToLower(o.Type) {
+	case OutputStdout, "":
+		return zapcore.AddSync(os.Stdout), nil, nil
+	case OutputStderr:
+		return zapcore.AddSync(os.Stderr), nil, nil
+	case OutputFile:
+		if o.Rotation != "" {
+			w, err := newRotateWriter(o)
+			if err != nil {
+				return nil, nil, err
+			}
+			return w, w, nil
+		}
+		if o.Filename == "" {
+			return nil, nil, errors.New("missing Filename")
+		}
+		hook := o.getLumberjack()
+		return zapcore.AddSync(hook), hook, nil
+	case OutputSocket:
+		if o.Network == "" || o.Address == "" {
+			return nil, nil, errors.New("missing Network or Address")
+		}
+		w := &socketWriter{network: o.Network, address: o.Address}
+		return w, w, nil
+	case OutputSyslog:
+		w := &socketWriter{network: o.Network, address: o.Address}
+		if w.network == "" {
+			w.network = SyslogNetwork
+		}
+		if w.address == "" {
+			w.address = SyslogAddress
+		}
+		return w, w, nil
+	case OutputMemory:
+		if o.Buffer == nil {
+			return nil, nil, errors.New("missing Buffer")
+		}
+		return o.Buffer, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown output type %q", o.Type)
+	}
+}
+
+// coreEnv holds the logger wide settings shared by the cores of all outputs
+type coreEnv struct {
+	level    zap.AtomicLevel
+	stats    *dropStats
+	async    *Async
+	redactor *redactor
+	encoder  *Encoder
+}
+
+func (o *Output) newCore(env *coreEnv) (zapcore.Core, io.Closer, error) {
+	enabler, err := o.levelEnabler(env.level)
+	if err != nil {
+		return nil, nil, err
+	}
+	ws, closer, err := o.writeSyncer()
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.BytesPerSecond > 0 {
+		ws = newRateLimitedWriteSyncer(ws, o.BytesPerSecond, env.stats)
+	}
+	if env.async != nil {
+		aws := newAsyncWriteSyncer(ws, env.async, env.stats)
+		// drain the queue before the output is closed
+		closers := multiCloser{aws}
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+		ws, closer = aws, closers
+	}
+	var core zapcore.Core
+	if strings.ToLower(o.Type) == OutputSyslog {
+		if core, err = o.newSyslogCore(o.encoder(env.encoder), ws, enabler); err != nil {
+			if closer != nil {
+				_ = closer.Close()
+			}
+			return nil, nil, err
+		}
+	} else {
+		core = zapcore.NewCore(o.encoder(env.encoder), ws, enabler)
+	}
+	if env.redactor != nil {
+		core = &redactCore{Core: core, redactor: env.redactor}
+	}
+	return core, closer, nil
+}
+
+func (o *Output) getLumberjack() *lumberjack.Logger {
+	maxSize := o.MaxSize
+	maxBackup := o.MaxBackups
+	maxAge := o.MaxAge
+
+	if maxSize == 0 {
+		maxSize = 1
+	}
+	if maxBackup == 0 {
+		maxBackup = 10
+	}
+	if maxAge == 0 {
+		maxAge = 10
+	}
+
+	return &lumberjack.Logger{
+		Filename:   o.Filename,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackup,
+		MaxAge:     maxAge,
+		Compress:   o.Compress,
+	}
+}
+
+// socketWriter dials lazily and redials after a failed write, an entry is dropped when the socket is unavailable
+type socketWriter struct {
+	network string
+	address string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (w *socketWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		conn, err := net.Dial(w.network, w.address)
+		if err != nil {
+			return 0, err
+		}
+		w.conn = conn
+	}
+	n, err := w.conn.Write(p)
+	if err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return n, err
+}
+
+func (w *socketWriter) Sync() error {
+	return nil
+}
+
+func (w *socketWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// MemoryBuffer keeps encoded entries in memory, safe for concurrent use
+type MemoryBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *MemoryBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *MemoryBuffer) Sync() error {
+	return nil
+}
+
+func (b *MemoryBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Lines returns the buffered entries, one per line
+func (b *MemoryBuffer) Lines() []string {
+	s := strings.TrimRight(b.String(), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func (b *MemoryBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
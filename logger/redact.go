@@ -0,0 +1,209 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 常用脱敏正则
+const (
+	RedactBearerToken = `(?i)bearer\s+[A-Za-z0-9._~+/-]+=*`
+	RedactJWT         = `eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`
+	RedactEmail       = `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`
+	RedactCardNumber  = `\b(?:4|5[1-5]|2[2-7]|3[47]|6)(?:[ -]?\d){12,18}\b` // 同时校验 Luhn, 避免误伤普通数字 ID
+)
+
+// Redaction 脱敏, 在编码前替换敏感字段的值, 以及消息和字符串字段中匹配正则的部分
+type Redaction struct {
+	Keys     []string `mapstructure:"keys"`     // 字段名, 不区分大小写, 值整体替换, 嵌套对象中同样生效
+	Patterns []string `mapstructure:"patterns"` // 正则
+	Mask     string   `mapstructure:"mask"`     // 默认 "******"
+}
+
+// DefaultRedaction masks common secret keys and all built-in patterns
+func DefaultRedaction() *Redaction {
+	return &Redaction{
+		Keys:     []string{"password", "passwd", "secret", "token", "access_token", "refresh_token", "authorization", "api_key", "apikey"},
+		Patterns: []string{RedactBearerToken, RedactJWT, RedactEmail, RedactCardNumber},
+	}
+}
+
+type redactor struct {
+	keys     map[string]bool
+	patterns []*regexp.Regexp
+	luhn     []bool // only replace matches passing the Luhn check
+	mask     string
+}
+
+func (r *Redaction) compile() *redactor {
+	rd := &redactor{keys: make(map[string]bool), mask: r.Mask}
+	if rd.mask == "" {
+		rd.mask = "******"
+	}
+	for _, key := range r.Keys {
+		rd.keys[strings.ToLower(key)] = true
+	}
+	for _, pattern := range r.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "logger: skip redaction pattern %q: %s\n", pattern, err.Error())
+			continue
+		}
+		rd.patterns = append(rd.patterns, re)
+		rd.luhn = append(rd.luhn, pattern == RedactCardNumber)
+	}
+	return rd
+}
+
+func (r *redactor) sensitive(key string) bool {
+	return r.keys[strings.ToLower(key)]
+}
+
+func (r *redactor) redactString(s string) string {
+	for i, re := range r.patterns {
+		if !r.luhn[i] {
+			s = re.ReplaceAllString(s, r.mask)
+			continue
+		}
+		s = re.ReplaceAllStringFunc(s, func(match string) string {
+			if luhnValid(match) {
+				return r.mask
+			}
+			return match
+		})
+	}
+	return s
+}
+
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func (r *redactor) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = r.redactField(field)
+	}
+	return redacted
+}
+
+func (r *redactor) redactField(field zapcore.Field) zapcore.Field {
+	if field.Type == zapcore.NamespaceType || field.Type == zapcore.SkipType {
+		return field
+	}
+	if r.sensitive(field.Key) {
+		return zap.String(field.Key, r.mask)
+	}
+	switch field.Type {
+	case zapcore.StringType:
+		return zap.String(field.Key, r.redactString(field.String))
+	case zapcore.ByteStringType:
+		return zap.ByteString(field.Key, []byte(r.redactString(string(field.Interface.([]byte)))))
+	case zapcore.StringerType:
+		return zap.String(field.Key, r.redactString(fmt.Sprint(field.Interface)))
+	case zapcore.ErrorType, zapcore.InlineMarshalerType:
+		// these add one or more top level keys, e.g. "error" and "errorVerbose"
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		return zap.Inline(redactedFields(r.redactValue(enc.Fields).(map[string]interface{})))
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.ReflectType:
+		// encode to plain values so nested keys and strings can be redacted
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		return zap.Any(field.Key, r.redactValue(enc.Fields[field.Key]))
+	default:
+		return field
+	}
+}
+
+func (r *redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case string:
+		return r.redactString(v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if r.sensitive(key) {
+				redacted[key] = r.mask
+				continue
+			}
+			redacted[key] = r.redactValue(item)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(item)
+		}
+		return redacted
+	default:
+		// structs and other reflected values, through their JSON form
+		b, err := json.Marshal(v)
+		if err != nil {
+			return r.redactString(fmt.Sprint(v))
+		}
+		var plain interface{}
+		if err := json.Unmarshal(b, &plain); err != nil {
+			return r.redactString(string(b))
+		}
+		return r.redactValue(plain)
+	}
+}
+
+type redactedFields map[string]interface{}
+
+func (f redactedFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for key, value := range f {
+		if err := enc.AddReflected(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactCore redacts the message and fields before the wrapped output core encodes them
+type redactCore struct {
+	zapcore.Core
+	redactor *redactor
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactor.redactFields(fields)), redactor: c.redactor}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.redactor.redactString(ent.Message)
+	return c.Core.Write(ent, c.redactor.redactFields(fields))
+}
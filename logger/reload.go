@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// generation is one build of the outputs of a Logger, replaced as a whole by Reload
+type generation struct {
+	core     zapcore.Core
+	closers  []io.Closer
+	redactor *redactor
+
+	mu     sync.RWMutex // held for reading while writing to core, for writing while closing
+	closed bool
+}
+
+// newGeneration builds the cores of the outputs, dropped entries are reported by l
+func (l *Logger) newGeneration(options *Options) *generation {
+	gen := &generation{}
+	stats := &dropStats{}
+	env := &coreEnv{level: l.level, stats: stats, async: options.Async, encoder: options.Encoder}
+	if options.Redaction != nil {
+		env.redactor = options.Redaction.compile()
+		gen.redactor = env.redactor
+	}
+	limited := false
+	var cores []zapcore.Core
+	for _, output := range options.outputs() {
+		limited = limited || output.BytesPerSecond > 0
+		core, closer, err := output.newCore(env)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "logger: skip %s output: %s\n", output.Type, err.Error())
+			continue
+		}
+		if closer != nil {
+			gen.closers = append(gen.closers, closer)
+		}
+		cores = append(cores, core)
+	}
+	gen.core = zapcore.NewTee(cores...)
+	if options.Sampling != nil {
+		gen.core = options.Sampling.wrap(gen.core, stats)
+	}
+
+	// 丢弃计数
+	if options.Sampling != nil || limited || (options.Async != nil && options.Async.dropping()) {
+		interval := options.DropReportInterval
+		if interval == 0 {
+			interval = time.Minute
+		}
+		// stop reporting before outputs are closed
+		gen.closers = append([]io.Closer{l.reportDropped(stats, interval)}, gen.closers...)
+	}
+	return gen
+}
+
+// close waits for the writes in progress, later writes are passed to the current generation
+func (g *generation) close() error {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+	err := g.core.Sync()
+	for _, closer := range g.closers {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// reloadRoot holds the current generation shared by all cores derived from a Logger
+type reloadRoot struct {
+	current atomic.Value // *generation
+}
+
+func (r *reloadRoot) load() *generation {
+	return r.current.Load().(*generation)
+}
+
+// reloadCore forwards to the current generation with its fields, so derived loggers follow Reload
+type reloadCore struct {
+	root   *reloadRoot
+	fields []zapcore.Field
+	cache  atomic.Value // *reloadCached
+}
+
+type reloadCached struct {
+	gen  *generation
+	core zapcore.Core
+}
+
+// current returns the core of the current generation with the fields added by With
+func (c *reloadCore) current() (*generation, zapcore.Core) {
+	gen := c.root.load()
+	if cached, ok := c.cache.Load().(*reloadCached); ok && cached.gen == gen {
+		return gen, cached.core
+	}
+	core := gen.core
+	if len(c.fields) != 0 {
+		core = core.With(c.fields)
+	}
+	c.cache.Store(&reloadCached{gen: gen, core: core})
+	return gen, core
+}
+
+func (c *reloadCore) Enabled(level zapcore.Level) bool {
+	_, core := c.current()
+	return core.Enabled(level)
+}
+
+func (c *reloadCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(all, c.fields...)
+	all = append(all, fields...)
+	return &reloadCore{root: c.root, fields: all}
+}
+
+func (c *reloadCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	gen, core := c.current()
+	if !core.Enabled(ent.Level) {
+		return ce
+	}
+	return ce.AddCore(ent, &generationCore{Core: core, gen: gen, reload: c})
+}
+
+func (c *reloadCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	_, core := c.current()
+	return core.Write(ent, fields)
+}
+
+func (c *reloadCore) Sync() error {
+	_, core := c.current()
+	return core.Sync()
+}
+
+// generationCore writes an entry checked against gen, or passes it to the current generation when gen is replaced meanwhile,
+// entries of a generation closed by Close are dropped
+type generationCore struct {
+	zapcore.Core
+	gen    *generation
+	reload *reloadCore
+}
+
+func (c *generationCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.gen.mu.RLock()
+	if !c.gen.closed {
+		defer c.gen.mu.RUnlock()
+		// check again so the output levels and sampling of gen apply
+		if ce := c.Core.Check(ent, nil); ce != nil {
+			ce.Write(fields...)
+		}
+		return nil
+	}
+	c.gen.mu.RUnlock()
+	if c.reload.root.load() == c.gen {
+		// closed by Close rather than replaced by Reload, there is nowhere left to write
+		return nil
+	}
+	if ce := c.reload.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+func Reload(options *Options) {
+	logger.Reload(options)
+}
+
+// Reload rebuilds the level and outputs of l and the loggers derived from it in place,
+// entries written meanwhile go to the old or the new outputs, the old outputs are closed after their queues drain.
+// Caller, Stacktrace and SpanEvents keep their initial values
+func (l *Logger) Reload(options *Options) {
+	if l.root == nil {
+		return
+	}
+	level, err := parseLevel(options.Level)
+	if err != nil {
+		level = zap.DebugLevel
+	}
+	gen := l.newGeneration(options)
+	old := l.root.load()
+	l.root.current.Store(gen)
+	l.level.SetLevel(level)
+	if err := old.close(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "logger: close replaced outputs: %s\n", err.Error())
+	}
+}
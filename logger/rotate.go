@@ -0,0 +1,420 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RotateDaily  = "daily"
+	RotateHourly = "hourly"
+	RotateSize   = "size"
+)
+
+const megabyte = 1024 * 1024
+
+// rotateWriter rotates files by time period and size, whichever comes first.
+// The file name is rendered from a pattern, see Output.Pattern.
+type rotateWriter struct {
+	rotation   string
+	pattern    string
+	symlink    string
+	location   *time.Location
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+	onRotate   func(closedFile string)
+
+	mu       sync.Mutex
+	file     *os.File
+	name     string
+	size     int64
+	periodAt time.Time // start of the current period
+	nextAt   time.Time // start of the next period, zero for size rotation
+	index    int
+	matcher  *regexp.Regexp
+	queue    []string        // rotated files waiting for afterRotate
+	pending  map[string]bool // rotated files not yet handled, kept by cleanup
+	closing  bool
+	wake     chan struct{}
+	done     chan struct{}
+}
+
+func newRotateWriter(o *Output) (*rotateWriter, error) {
+	w := &rotateWriter{
+		rotation:   strings.ToLower(o.Rotation),
+		pattern:    o.Pattern,
+		symlink:    o.Symlink,
+		location:   time.Local,
+		maxSize:    int64(o.MaxSize) * megabyte,
+		maxBackups: o.MaxBackups,
+		maxAge:     time.Duration(o.MaxAge) * 24 * time.Hour,
+		compress:   o.Compress,
+		onRotate:   o.OnRotate,
+	}
+	if o.UTC {
+		w.location = time.UTC
+	}
+	switch w.rotation {
+	case RotateDaily, RotateHourly:
+	case RotateSize:
+		if w.maxSize == 0 {
+			w.maxSize = megabyte
+		}
+	default:
+		return nil, fmt.Errorf("unknown rotation %q", o.Rotation)
+	}
+	if w.pattern == "" {
+		if o.Filename == "" {
+			return nil, fmt.Errorf("missing Pattern or Filename")
+		}
+		w.pattern = defaultPattern(o.Filename, w.rotation)
+	}
+	if strings.Contains(filepath.Dir(w.pattern), "%") {
+		return nil, fmt.Errorf("pattern %q: time and index only supported in the file name", w.pattern)
+	}
+	w.matcher = patternMatcher(filepath.Base(w.pattern))
+	w.pending = make(map[string]bool)
+	w.wake = make(chan struct{}, 1)
+	w.done = make(chan struct{})
+	go w.handleRotated()
+	return w, nil
+}
+
+// defaultPattern turns "./app.log" into "./app-%Y-%m-%d.log" for daily rotation
+func defaultPattern(filename, rotation string) string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	switch rotation {
+	case RotateDaily:
+		return base + "-%Y-%m-%d" + ext
+	case RotateHourly:
+		return base + "-%Y-%m-%d-%H" + ext
+	default:
+		return filename
+	}
+}
+
+// render replaces %Y %m %d %H %M with the period time and %i with the index,
+// without %i a non-zero index is inserted before the extension, like "app-2026-10-18.1.log"
+func (w *rotateWriter) render(t time.Time, index int) string {
+	name := strings.ReplaceAll(renderTime(w.pattern, t), "%i", strconv.Itoa(index))
+	if index > 0 && !strings.Contains(w.pattern, "%i") {
+		ext := filepath.Ext(name)
+		name = strings.TrimSuffix(name, ext) + "." + strconv.Itoa(index) + ext
+	}
+	return name
+}
+
+func renderTime(pattern string, t time.Time) string {
+	return strings.NewReplacer(
+		"%Y", fmt.Sprintf("%04d", t.Year()),
+		"%m", fmt.Sprintf("%02d", int(t.Month())),
+		"%d", fmt.Sprintf("%02d", t.Day()),
+		"%H", fmt.Sprintf("%02d", t.Hour()),
+		"%M", fmt.Sprintf("%02d", t.Minute()),
+	).Replace(pattern)
+}
+
+// lastIndex returns the highest index of the files already written in the current period,
+// so a restarted process continues after them
+func (w *rotateWriter) lastIndex() int {
+	base := renderTime(filepath.Base(w.pattern), w.periodAt)
+	var expr string
+	if strings.Contains(base, "%i") {
+		expr = strings.ReplaceAll(regexp.QuoteMeta(base), "%i", `(\d+)`)
+	} else {
+		ext := filepath.Ext(base)
+		expr = regexp.QuoteMeta(strings.TrimSuffix(base, ext)) + `(?:\.(\d+))?` + regexp.QuoteMeta(ext)
+	}
+	re := regexp.MustCompile(`^` + expr + `(?:\.gz)?$`)
+	entries, err := os.ReadDir(filepath.Dir(w.pattern))
+	if err != nil {
+		return 0
+	}
+	last := 0
+	for _, entry := range entries {
+		match := re.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if index, err := strconv.Atoi(match[1]); err == nil && index > last {
+			last = index
+		}
+	}
+	return last
+}
+
+// patternMatcher matches the file names rendered from pattern, including compressed ones
+func patternMatcher(pattern string) *regexp.Regexp {
+	ext := filepath.Ext(pattern)
+	base := strings.TrimSuffix(pattern, ext)
+	expr := regexp.QuoteMeta(base)
+	if !strings.Contains(pattern, "%i") {
+		expr += `(\.\d+)?`
+	}
+	expr += regexp.QuoteMeta(ext)
+	expr = strings.NewReplacer(
+		"%Y", `\d{4}`,
+		"%m", `\d{2}`,
+		"%d", `\d{2}`,
+		"%H", `\d{2}`,
+		"%M", `\d{2}`,
+		"%i", `\d+`,
+	).Replace(expr)
+	return regexp.MustCompile(`^` + expr + `(\.gz)?$`)
+}
+
+func (w *rotateWriter) period(now time.Time) (start, next time.Time) {
+	t := now.In(w.location)
+	switch w.rotation {
+	case RotateDaily:
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, w.location)
+		return start, start.AddDate(0, 0, 1)
+	case RotateHourly:
+		start = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, w.location)
+		return start, start.Add(time.Hour)
+	default:
+		return t, time.Time{}
+	}
+}
+
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closing {
+		return 0, os.ErrClosed
+	}
+
+	now := time.Now()
+	switch {
+	case w.file == nil:
+		w.periodAt, w.nextAt = w.period(now)
+		if err := w.open(w.lastIndex()); err != nil {
+			return 0, err
+		}
+	case !w.nextAt.IsZero() && !now.Before(w.nextAt):
+		w.periodAt, w.nextAt = w.period(now)
+		if err := w.rotate(0); err != nil {
+			return 0, err
+		}
+	case w.maxSize > 0 && w.size+int64(len(p)) > w.maxSize && w.size > 0:
+		if err := w.rotate(w.index + 1); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// open appends to the first file of the period from index on which is neither compressed nor full
+func (w *rotateWriter) open(index int) error {
+	name := w.render(w.periodAt, index)
+	for {
+		_, err := os.Stat(name + ".gz")
+		compressed := err == nil
+		info, err := os.Stat(name)
+		full := err == nil && w.maxSize > 0 && info.Size() >= w.maxSize
+		if !compressed && !full {
+			break
+		}
+		index++
+		name = w.render(w.periodAt, index)
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file, w.name, w.size, w.index = file, name, info.Size(), index
+	w.link()
+	return nil
+}
+
+func (w *rotateWriter) link() {
+	if w.symlink == "" {
+		return
+	}
+	target, err := filepath.Rel(filepath.Dir(w.symlink), w.name)
+	if err != nil {
+		target, _ = filepath.Abs(w.name)
+	}
+	tmp := w.symlink + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "logger: symlink %s: %s\n", w.symlink, err.Error())
+		return
+	}
+	// rename replaces the old link atomically
+	if err := os.Rename(tmp, w.symlink); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "logger: symlink %s: %s\n", w.symlink, err.Error())
+	}
+}
+
+func (w *rotateWriter) rotate(index int) error {
+	closed := w.name
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	if err := w.open(index); err != nil {
+		return err
+	}
+	w.pending[filepath.Base(closed)] = true
+	w.queue = append(w.queue, closed)
+	w.notify()
+	return nil
+}
+
+func (w *rotateWriter) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// handleRotated runs afterRotate for rotated files one by one, in the order they were closed
+func (w *rotateWriter) handleRotated() {
+	defer close(w.done)
+	for range w.wake {
+		w.mu.Lock()
+		queue, closing := w.queue, w.closing
+		w.queue = nil
+		w.mu.Unlock()
+
+		for _, closed := range queue {
+			w.afterRotate(closed)
+			w.mu.Lock()
+			delete(w.pending, filepath.Base(closed))
+			w.mu.Unlock()
+			w.cleanup()
+		}
+		if closing {
+			return
+		}
+	}
+}
+
+// afterRotate compresses the closed file and hands it to OnRotate
+func (w *rotateWriter) afterRotate(closed string) {
+	if w.compress {
+		if err := compressFile(closed); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "logger: compress %s: %s\n", closed, err.Error())
+		} else {
+			closed += ".gz"
+		}
+	}
+	if w.onRotate != nil {
+		w.onRotate(closed)
+	}
+}
+
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+func (w *rotateWriter) cleanup() {
+	if w.maxBackups == 0 && w.maxAge == 0 {
+		return
+	}
+	dir := filepath.Dir(w.pattern)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	skip := map[string]bool{filepath.Base(w.name): true}
+	for name := range w.pending {
+		skip[name] = true
+	}
+	w.mu.Unlock()
+
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, entry := range entries {
+		if entry.IsDir() || skip[entry.Name()] || !w.matcher.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, entry.Name()), modTime: info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+	for i, b := range backups {
+		expired := w.maxAge > 0 && time.Since(b.modTime) > w.maxAge
+		if (w.maxBackups > 0 && i >= w.maxBackups) || expired {
+			_ = os.Remove(b.path)
+		}
+	}
+}
+
+func (w *rotateWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close closes the current file and waits for rotated files to be handled
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.closing = true
+	w.notify()
+	w.mu.Unlock()
+	<-w.done
+	return err
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
+)
+
+// Sampling 对同一级别同一消息的日志采样
+// Initial 和 Thereafter 都不大于 0 时使用默认值 100 和 100
+type Sampling struct {
+	Initial    int           `mapstructure:"initial"`    // 每个 Tick 内先输出的条数
+	Thereafter int           `mapstructure:"thereafter"` // 之后每 Thereafter 条输出一条, 0 表示全部丢弃
+	Tick       time.Duration `mapstructure:"tick"`       // 默认 1s
+}
+
+// samplingDropAll is passed to zap as thereafter to drop every entry after initial,
+// zap divides by thereafter so 0 cannot be passed, and its counters reset every tick long before reaching this
+const samplingDropAll = math.MaxInt32
+
+// dropStats counts entries dropped by sampling, output byte rate caps and async queue overflow
+type dropStats struct {
+	sampled     uint64
+	rateLimited uint64
+	overflow    uint64
+}
+
+func (s *Sampling) wrap(core zapcore.Core, stats *dropStats) zapcore.Core {
+	tick := s.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+	initial, thereafter := s.Initial, s.Thereafter
+	switch {
+	case initial <= 0 && thereafter <= 0:
+		initial, thereafter = 100, 100
+	case thereafter <= 0:
+		thereafter = samplingDropAll
+	case initial < 0:
+		initial = 0
+	}
+	return zapcore.NewSamplerWithOptions(core, tick, initial, thereafter,
+		zapcore.SamplerHook(func(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+			if dec&zapcore.LogDropped > 0 {
+				atomic.AddUint64(&stats.sampled, 1)
+			}
+		}))
+}
+
+// rateLimitedWriteSyncer drops writes exceeding the bytes per second cap, a write is one entry.
+// An entry larger than the cap, such as one with a long stacktrace, is written when the budget of a whole second is left
+type rateLimitedWriteSyncer struct {
+	zapcore.WriteSyncer
+	limiter *rate.Limiter
+	stats   *dropStats
+}
+
+func newRateLimitedWriteSyncer(ws zapcore.WriteSyncer, bytesPerSecond int, stats *dropStats) zapcore.WriteSyncer {
+	return &rateLimitedWriteSyncer{
+		WriteSyncer: ws,
+		limiter:     rate.NewLimiter(rate.Limit(bytesPerSecond), bytesPerSecond),
+		stats:       stats,
+	}
+}
+
+func (w *rateLimitedWriteSyncer) Write(p []byte) (int, error) {
+	n := len(p)
+	if burst := w.limiter.Burst(); n > burst {
+		n = burst
+	}
+	if !w.limiter.AllowN(time.Now(), n) {
+		atomic.AddUint64(&w.stats.rateLimited, 1)
+		return len(p), nil
+	}
+	return w.WriteSyncer.Write(p)
+}
+
+// reportDropped emits the dropped counters every interval until the returned closer is closed,
+// the report is a log entry like the others, sampling and the byte rate caps may drop it too
+func (l *Logger) reportDropped(stats *dropStats, interval time.Duration) closerFunc {
+	done := make(chan struct{})
+	// called from this goroutine, not through a Logger method
+	reporter := l.logger.Desugar().WithOptions(zap.AddCallerSkip(-1)).Sugar()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			sampled := atomic.SwapUint64(&stats.sampled, 0)
+			rateLimited := atomic.SwapUint64(&stats.rateLimited, 0)
+			overflow := atomic.SwapUint64(&stats.overflow, 0)
+			if sampled+rateLimited+overflow == 0 {
+				continue
+			}
+			reporter.Warnw("log entries dropped", "sampled", sampled, "rate_limited", rateLimited, "overflow", overflow)
+		}
+	}()
+	return func() error {
+		close(done)
+		return nil
+	}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampledLines(sampling *Sampling, n int) int {
+	buf := &MemoryBuffer{}
+	l := GetLoggerByOptions(&Options{
+		Sampling: sampling,
+		Outputs:  []Output{{Type: OutputMemory, Buffer: buf}},
+	})
+	for i := 0; i < n; i++ {
+		l.Info("same message")
+	}
+	return len(buf.Lines())
+}
+
+func TestSamplingDefaults(t *testing.T) {
+	// a config with only tick set must neither panic nor drop everything
+	if got := sampledLines(&Sampling{Tick: time.Minute}, 150); got != 100 {
+		t.Fatalf("got %d lines, want 100", got)
+	}
+}
+
+func TestSamplingDropAllAfterInitial(t *testing.T) {
+	if got := sampledLines(&Sampling{Initial: 3, Tick: time.Minute}, 50); got != 3 {
+		t.Fatalf("got %d lines, want 3", got)
+	}
+}
+
+func TestRateLimitLargeEntry(t *testing.T) {
+	buf := &MemoryBuffer{}
+	l := GetLoggerByOptions(&Options{
+		Outputs: []Output{{Type: OutputMemory, Buffer: buf, BytesPerSecond: 64}},
+	})
+	l.Info(strings.Repeat("x", 256))
+	if got := len(buf.Lines()); got != 1 {
+		t.Fatalf("entry larger than BytesPerSecond: got %d lines, want 1", got)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !windows
+// +build !windows
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+func WatchLevelSignals() (stop func()) {
+	return logger.WatchLevelSignals()
+}
+
+// WatchLevelSignals switches to debug level on SIGUSR1 and back to the level before on SIGUSR2,
+// call stop to restore the default signal behavior
+func (l *Logger) WatchLevelSignals() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		previous := l.level.Level()
+		debug := false
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				switch {
+				case sig == syscall.SIGUSR1 && !debug:
+					previous = l.level.Level()
+					l.level.SetLevel(zap.DebugLevel)
+					debug = true
+				case sig == syscall.SIGUSR2 && debug:
+					l.level.SetLevel(previous)
+					debug = false
+				}
+				l.Infof("log level changed to %s by signal %s", l.GetLevel(), sig)
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
@@ -0,0 +1,10 @@
+package logger
+
+// WatchLevelSignals is a no-op, SIGUSR1 and SIGUSR2 do not exist on windows
+func WatchLevelSignals() (stop func()) {
+	return logger.WatchLevelSignals()
+}
+
+func (l *Logger) WatchLevelSignals() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,126 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler writes slog records to the core of a Logger, so they share its level, outputs and fields
+type slogHandler struct {
+	core   zapcore.Core
+	caller bool
+}
+
+func SlogHandler() slog.Handler {
+	return logger.SlogHandler()
+}
+
+// SlogHandler returns a slog.Handler backed by l, e.g. slog.SetDefault(slog.New(l.SlogHandler()))
+func (l *Logger) SlogHandler() slog.Handler {
+	return &slogHandler{
+		core:   l.logger.With(l.args...).Desugar().Core(),
+		caller: l.caller,
+	}
+}
+
+func slogLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.core.Enabled(slogLevel(level))
+}
+
+func (h *slogHandler) Handle(_ context.Context, record slog.Record) error {
+	entry := zapcore.Entry{
+		Level:   slogLevel(record.Level),
+		Time:    record.Time,
+		Message: record.Message,
+	}
+	if h.caller && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		entry.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+	ce := h.core.Check(entry, nil)
+	if ce == nil {
+		return nil
+	}
+	fields := make([]zap.Field, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = append(fields, slogField(attr))
+		return true
+	})
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zap.Field, 0, len(attrs))
+	for _, attr := range attrs {
+		fields = append(fields, slogField(attr))
+	}
+	return &slogHandler{core: h.core.With(fields), caller: h.caller}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{core: h.core.With([]zap.Field{zap.Namespace(name)}), caller: h.caller}
+}
+
+func slogField(attr slog.Attr) zap.Field {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return zap.String(attr.Key, value.String())
+	case slog.KindInt64:
+		return zap.Int64(attr.Key, value.Int64())
+	case slog.KindUint64:
+		return zap.Uint64(attr.Key, value.Uint64())
+	case slog.KindFloat64:
+		return zap.Float64(attr.Key, value.Float64())
+	case slog.KindBool:
+		return zap.Bool(attr.Key, value.Bool())
+	case slog.KindDuration:
+		return zap.Duration(attr.Key, value.Duration())
+	case slog.KindTime:
+		return zap.Time(attr.Key, value.Time())
+	case slog.KindGroup:
+		group := slogGroup(value.Group())
+		if attr.Key == "" {
+			return zap.Inline(group)
+		}
+		return zap.Object(attr.Key, group)
+	default:
+		if err, ok := value.Any().(error); ok {
+			return zap.NamedError(attr.Key, err)
+		}
+		return zap.Any(attr.Key, value.Any())
+	}
+}
+
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, attr := range g {
+		slogField(attr).AddTo(enc)
+	}
+	return nil
+}
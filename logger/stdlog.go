@@ -0,0 +1,14 @@
+package logger
+
+import "go.uber.org/zap"
+
+func RedirectStdLog() (restore func()) {
+	return logger.RedirectStdLog()
+}
+
+// RedirectStdLog sends the output of the standard library log package to l at info level, until restore is called
+func (l *Logger) RedirectStdLog() (restore func()) {
+	// zap counts the frames of the log package itself, drop the skip added for the Logger wrapper methods
+	base := l.logger.With(l.args...).Desugar().WithOptions(zap.AddCallerSkip(-1))
+	return zap.RedirectStdLog(base)
+}
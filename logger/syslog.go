@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// syslog 默认地址
+const (
+	SyslogNetwork = "unixgram"
+	SyslogAddress = "/dev/log"
+)
+
+var syslogPool = buffer.NewPool()
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverity maps zap levels to the RFC 5424 severities
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7 // debug
+	case zapcore.InfoLevel:
+		return 6 // informational
+	case zapcore.WarnLevel:
+		return 4 // warning
+	case zapcore.ErrorLevel:
+		return 3 // error
+	case zapcore.DPanicLevel:
+		return 2 // critical
+	case zapcore.PanicLevel:
+		return 1 // alert
+	case zapcore.FatalLevel:
+		return 0 // emergency
+	default:
+		return 5 // notice
+	}
+}
+
+// syslogCore frames each encoded entry as an RFC 5424 message, one write per message
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc      zapcore.Encoder
+	out      zapcore.WriteSyncer
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	network  string
+}
+
+func (o *Output) newSyslogCore(enc zapcore.Encoder, ws zapcore.WriteSyncer, enabler zapcore.LevelEnabler) (*syslogCore, error) {
+	facility := 1
+	if o.Facility != "" {
+		var ok bool
+		if facility, ok = syslogFacilities[strings.ToLower(o.Facility)]; !ok {
+			return nil, fmt.Errorf("unknown syslog facility %q", o.Facility)
+		}
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	appName := o.AppName
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+	network := strings.ToLower(o.Network)
+	if network == "" {
+		network = SyslogNetwork
+	}
+	return &syslogCore{
+		LevelEnabler: enabler,
+		enc:          enc,
+		out:          ws,
+		facility:     facility,
+		hostname:     syslogHeaderField(hostname, 255),
+		appName:      syslogHeaderField(appName, 48),
+		procID:       strconv.Itoa(os.Getpid()),
+		network:      network,
+	}, nil
+}
+
+// syslogHeaderField keeps the printable ASCII allowed in header fields, "-" when empty
+func syslogHeaderField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	msg, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer msg.Free()
+
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	line := syslogPool.Get()
+	defer line.Free()
+	line.AppendByte('<')
+	line.AppendInt(int64(c.facility*8 + syslogSeverity(ent.Level)))
+	line.AppendString(">1 ")
+	line.AppendString(ent.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	line.AppendByte(' ')
+	line.AppendString(c.hostname)
+	line.AppendByte(' ')
+	line.AppendString(c.appName)
+	line.AppendByte(' ')
+	line.AppendString(c.procID)
+	line.AppendString(" - - ")
+	line.AppendString(strings.TrimRight(msg.String(), "\n"))
+
+	switch c.network {
+	case "tcp", "tcp4", "tcp6":
+		// RFC 6587 octet counting
+		_, err = c.out.Write([]byte(strconv.Itoa(line.Len()) + " " + line.String()))
+	case "unix":
+		line.AppendByte('\n')
+		_, err = c.out.Write(line.Bytes())
+	default:
+		_, err = c.out.Write(line.Bytes())
+	}
+	if err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		// like zap's own core, sync before a panic or fatal exit
+		return c.out.Sync()
+	}
+	return nil
+}
+
+func (c *syslogCore) Sync() error {
+	return c.out.Sync()
+}
@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TB is the part of testing.TB used by TestLogger, *testing.T and *testing.B satisfy it
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Cleanup(func())
+}
+
+// TestLogger records entries in memory instead of writing them, for asserting on logs in tests
+type TestLogger struct {
+	*Logger
+	t    TB
+	logs *observer.ObservedLogs
+}
+
+// NewTestLogger returns a debug level logger recording entries with their fields and callers
+func NewTestLogger(t TB) *TestLogger {
+	atomicLevel := zap.NewAtomicLevelAt(zap.DebugLevel)
+	core, logs := observer.New(atomicLevel)
+	l := &Logger{level: atomicLevel, caller: true}
+	l.setZap(zap.New(core, zap.AddCallerSkip(1), zap.AddCaller()).Sugar())
+	return &TestLogger{
+		Logger: l,
+		t:      t,
+		logs:   logs,
+	}
+}
+
+// ReplaceGlobal sets l as the package level logger, returns a func restoring the previous one
+func ReplaceGlobal(l *Logger) func() {
+	prev, prevStd := logger, std
+	setLogger(l)
+	return func() {
+		logger, std = prev, prevStd
+	}
+}
+
+// ReplaceGlobal sets tl as the package level logger until the test ends
+func (tl *TestLogger) ReplaceGlobal() *TestLogger {
+	tl.t.Cleanup(ReplaceGlobal(tl.Logger))
+	return tl
+}
+
+// Entries returns all recorded entries, fields are in Context
+func (tl *TestLogger) Entries() []observer.LoggedEntry {
+	return tl.logs.All()
+}
+
+// Messages returns the messages of all recorded entries
+func (tl *TestLogger) Messages() []string {
+	entries := tl.logs.All()
+	messages := make([]string, len(entries))
+	for i, entry := range entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+// Reset removes all recorded entries
+func (tl *TestLogger) Reset() {
+	tl.logs.TakeAll()
+}
+
+// Filter returns the entries with the level and message, an empty level or message matches any
+func (tl *TestLogger) Filter(level, msg string) []observer.LoggedEntry {
+	var lvl zapcore.Level
+	if level != "" {
+		var err error
+		if lvl, err = parseLevel(level); err != nil {
+			tl.t.Helper()
+			tl.t.Errorf("logger: %s", err.Error())
+			return nil
+		}
+	}
+	return tl.logs.Filter(func(entry observer.LoggedEntry) bool {
+		return (level == "" || entry.Level == lvl) && (msg == "" || entry.Message == msg)
+	}).All()
+}
+
+// AssertLogged fails the test when no entry has the level and message
+func (tl *TestLogger) AssertLogged(level, msg string) bool {
+	tl.t.Helper()
+	if len(tl.Filter(level, msg)) == 0 {
+		tl.t.Errorf("logger: no %s entry %q, logged:\n%s", level, msg, tl.dump())
+		return false
+	}
+	return true
+}
+
+// AssertNotLogged fails the test when an entry has the level and message
+func (tl *TestLogger) AssertNotLogged(level, msg string) bool {
+	tl.t.Helper()
+	if len(tl.Filter(level, msg)) != 0 {
+		tl.t.Errorf("logger: unexpected %s entry %q, logged:\n%s", level, msg, tl.dump())
+		return false
+	}
+	return true
+}
+
+// AssertCount fails the test when the number of entries with the level and message is not n
+func (tl *TestLogger) AssertCount(level, msg string, n int) bool {
+	tl.t.Helper()
+	if count := len(tl.Filter(level, msg)); count != n {
+		tl.t.Errorf("logger: %d %s entries %q, want %d, logged:\n%s", count, level, msg, n, tl.dump())
+		return false
+	}
+	return true
+}
+
+// AssertField fails the test when no entry with the message has the field, numbers match regardless of their type
+func (tl *TestLogger) AssertField(msg, key string, value interface{}) bool {
+	tl.t.Helper()
+	for _, entry := range tl.Filter("", msg) {
+		if actual, ok := entry.ContextMap()[key]; ok && fieldEqual(actual, value) {
+			return true
+		}
+	}
+	tl.t.Errorf("logger: no entry %q with %s=%v, logged:\n%s", msg, key, value, tl.dump())
+	return false
+}
+
+// AssertHasField fails the test when no entry with the message has the field key
+func (tl *TestLogger) AssertHasField(msg, key string) bool {
+	tl.t.Helper()
+	for _, entry := range tl.Filter("", msg) {
+		if _, ok := entry.ContextMap()[key]; ok {
+			return true
+		}
+	}
+	tl.t.Errorf("logger: no entry %q with field %s, logged:\n%s", msg, key, tl.dump())
+	return false
+}
+
+func fieldEqual(actual, expected interface{}) bool {
+	if reflect.DeepEqual(actual, expected) {
+		return true
+	}
+	// fields are recorded as int64, float64, string ... the expected value may be an int
+	return fmt.Sprint(actual) == fmt.Sprint(expected)
+}
+
+func (tl *TestLogger) dump() string {
+	var b strings.Builder
+	for _, entry := range tl.logs.All() {
+		_, _ = fmt.Fprintf(&b, "  %s %q %v\n", entry.Level, entry.Message, entry.ContextMap())
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// Ctx returns the logger of ctx, see FromContext, with the trace of ctx
+func Ctx(ctx context.Context) *Logger {
+	return FromContext(ctx).Ctx(ctx)
+}
+
+// Ctx returns a child logger adding trace_id and span_id when ctx carries an OpenTelemetry span,
+// with SpanEvents its error and above level logs are also recorded as events of the span
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	if ctx == nil {
+		return l
+	}
+	span := trace.SpanFromContext(ctx)
+	spanContext := span.SpanContext()
+	if !spanContext.IsValid() {
+		return l
+	}
+	ln := l.WithField("trace_id", spanContext.TraceID().String()).WithField("span_id", spanContext.SpanID().String())
+	if l.spanEvents && span.IsRecording() {
+		ln.span = span
+	}
+	return ln
+}
+
+func (l *Logger) addSpanEvent(level zapcore.Level, msg string) {
+	redactor := l.redactor()
+	if redactor != nil {
+		msg = redactor.redactString(msg)
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("log.severity", level.String()),
+		attribute.String("log.message", msg),
+	}
+	for i := 0; i+1 < len(l.args); i += 2 {
+		key, ok := l.args[i].(string)
+		if !ok || key == "trace_id" || key == "span_id" {
+			continue
+		}
+		value := fmt.Sprint(l.args[i+1])
+		if redactor != nil {
+			value = redactor.redactString(value)
+			if redactor.sensitive(key) {
+				value = redactor.mask
+			}
+		}
+		attrs = append(attrs, attribute.String(key, value))
+	}
+	l.span.AddEvent("log", trace.WithAttributes(attrs...))
+}
+
+// redactor returns the redaction of the current outputs, so span events follow Reload
+func (l *Logger) redactor() *redactor {
+	if l.root == nil {
+		return nil
+	}
+	return l.root.load().redactor
+}
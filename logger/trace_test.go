@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingSpan records the attributes of its events
+type recordingSpan struct {
+	trace.Span
+	sc     trace.SpanContext
+	events [][]attribute.KeyValue
+}
+
+func (s *recordingSpan) SpanContext() trace.SpanContext { return s.sc }
+
+func (s *recordingSpan) IsRecording() bool { return true }
+
+func (s *recordingSpan) AddEvent(name string, options ...trace.EventOption) {
+	config := trace.NewEventConfig(options...)
+	s.events = append(s.events, config.Attributes())
+}
+
+func TestSpanEventRedactionFollowsReload(t *testing.T) {
+	span := &recordingSpan{
+		Span: trace.SpanFromContext(context.Background()),
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceFlags: trace.FlagsSampled,
+		}),
+	}
+	ctx := trace.ContextWithSpan(context.Background(), span)
+
+	l := GetLoggerByOptions(&Options{Level: "debug", SpanEvents: true})
+	ln := l.Ctx(ctx).WithField("password", "secret")
+	l.Reload(&Options{Level: "debug", SpanEvents: true, Redaction: DefaultRedaction()})
+	ln.Error("login failed")
+
+	if len(span.events) != 1 {
+		t.Fatalf("got %d span events, want 1", len(span.events))
+	}
+	for _, attr := range span.events[0] {
+		if attr.Key == "password" && attr.Value.AsString() == "secret" {
+			t.Errorf("password not redacted after Reload: %v", span.events[0])
+		}
+	}
+}